@@ -0,0 +1,123 @@
+package errorType
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BulkError is returned when some models of a bulk write, or documents of an insert many, failed.
+// The results of the models that succeeded are returned alongside it.
+// It matches a category with errors.Is, and the Is* functions, only if all failed models have that category.
+type BulkError struct {
+	Collection string
+	// Errors of the failed models in the order of their index. Each error is classified like ParseAndReturnDBError.
+	Errors []BulkWriteError
+	// The write concern error of the operation, if any.
+	WriteConcernError error
+
+	cause error
+}
+
+type BulkWriteError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, writeErr := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("[%d] %s", writeErr.Index, writeErr.Err.Error()))
+	}
+	if e.WriteConcernError != nil {
+		msgs = append(msgs, fmt.Sprintf("write concern: %s", e.WriteConcernError.Error()))
+	}
+	return fmt.Sprintf("%s bulk write failed for %d model(s): ", e.Collection, len(e.Errors)) + strings.Join(msgs, ", ")
+}
+
+func (e *BulkError) Unwrap() error {
+	return e.cause
+}
+
+// Failed returns the error of the model at the index, or nil if it did not fail.
+func (e *BulkError) Failed(index int) error {
+	for _, writeErr := range e.Errors {
+		if writeErr.Index == index {
+			return writeErr.Err
+		}
+	}
+	return nil
+}
+
+// FailedIndexes returns the indexes of the failed models.
+func (e *BulkError) FailedIndexes() []int {
+	indexes := make([]int, len(e.Errors))
+	for i, writeErr := range e.Errors {
+		indexes[i] = writeErr.Index
+	}
+	return indexes
+}
+
+// every reports whether there are failed models and all their errors satisfy match.
+func (e *BulkError) every(match func(err error) bool) bool {
+	if len(e.Errors) == 0 {
+		return false
+	}
+	for _, writeErr := range e.Errors {
+		if !match(writeErr.Err) {
+			return false
+		}
+	}
+	return true
+}
+
+func IsBulkErr(err error) bool {
+	return errors.Is(err, ErrBulk)
+}
+
+// ParseAndReturnBulkError returns BulkError if err is a mongo.BulkWriteException with write errors,
+// and the result of ParseAndReturnDBError otherwise.
+// The write models in update, or the documents in doc, are used as the document of the error of each model.
+func ParseAndReturnBulkError(err error, collection string, update, doc interface{}) error {
+	var bulkException mongo.BulkWriteException
+	if !errors.As(err, &bulkException) || len(bulkException.WriteErrors) == 0 {
+		return ParseAndReturnDBError(err, collection, nil, update, doc)
+	}
+
+	models := update
+	if models == nil {
+		models = doc
+	}
+	bulkErr := &BulkError{Collection: collection, cause: err}
+	if bulkException.WriteConcernError != nil {
+		bulkErr.WriteConcernError = bulkException.WriteConcernError
+	}
+	for _, writeErr := range bulkException.WriteErrors {
+		// the given models keep the sensitive tags of their structs, which the request may have lost in a stamped copy
+		doc := modelAt(models, writeErr.Index)
+		if doc == nil {
+			doc = writeErr.Request
+		}
+		bulkErr.Errors = append(bulkErr.Errors, BulkWriteError{
+			Index: writeErr.Index,
+			Err:   ParseAndReturnDBError(writeErr.WriteError, collection, nil, nil, doc),
+		})
+	}
+	return bulkErr
+}
+
+func modelAt(models interface{}, index int) interface{} {
+	switch m := models.(type) {
+	case []mongo.WriteModel:
+		if index < len(m) {
+			return m[index]
+		}
+	case []interface{}:
+		if index < len(m) {
+			return m[index]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package errorType
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_ParseAndReturnBulkError(t *testing.T) {
+	documents := []interface{}{"a", "b", "c", "d"}
+	bulkException := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}},
+		{WriteError: mongo.WriteError{Index: 3, Code: 121, Message: "document failed validation"}},
+	}}
+
+	err := ParseAndReturnBulkError(bulkException, "col", nil, documents)
+	assert.True(t, IsBulkErr(err))
+	assert.True(t, IsBulkErr(errors.Wrap(err, "")))
+
+	var bulkErr *BulkError
+	assert.True(t, errors.As(err, &bulkErr))
+	assert.Equal(t, []int{1, 3}, bulkErr.FailedIndexes())
+	assert.True(t, IsDuplicatedKeyErr(bulkErr.Failed(1)))
+	assert.True(t, IsDocumentValidationErr(bulkErr.Failed(3)))
+	assert.Nil(t, bulkErr.Failed(0))
+	assert.Contains(t, bulkErr.Failed(1).Error(), "b")
+
+	var cause mongo.BulkWriteException
+	assert.True(t, errors.As(err, &cause))
+}
+
+func Test_ParseAndReturnBulkError_NotBulk(t *testing.T) {
+	err := ParseAndReturnBulkError(errors.New("test"), "col", nil, nil)
+	assert.False(t, IsBulkErr(err))
+	assert.True(t, IsDBInternalErr(err))
+
+	err = ParseAndReturnBulkError(mongo.BulkWriteException{WriteConcernError: &mongo.WriteConcernError{Code: 64}}, "col", nil, nil)
+	assert.False(t, IsBulkErr(err))
+}
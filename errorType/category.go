@@ -0,0 +1,73 @@
+package errorType
+
+import "github.com/pkg/errors"
+
+// Categories of the errors returned by the wrapper. Every error matches its category with errors.Is.
+//
+//	if errors.Is(err, errorType.ErrNotFound) {}
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrDuplicateKey       = errors.New("duplicate key")
+	ErrTimeout            = errors.New("timeout")
+	ErrDecode             = errors.New("decode")
+	ErrInternal           = errors.New("internal")
+	ErrDocumentValidation = errors.New("document validation")
+	ErrVersionConflict    = errors.New("version conflict")
+	ErrMongoClient        = errors.New("mongo client")
+	ErrBulk               = errors.New("bulk write")
+	ErrAudit              = errors.New("audit")
+)
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *duplicatedKeyError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+func (e *decodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+func (e *internalError) Is(target error) bool {
+	return target == ErrInternal
+}
+
+func (e *documentValidationError) Is(target error) bool {
+	return target == ErrDocumentValidation
+}
+
+func (e *versionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+func (e *auditError) Is(target error) bool {
+	return target == ErrAudit
+}
+
+func (e *mongoClientError) Is(target error) bool {
+	return target == ErrMongoClient
+}
+
+// Is reports whether target is ErrBulk or matches the errors of all failed models.
+// A bulk error whose models failed for different reasons matches ErrBulk only; inspect Errors for each model.
+func (e *BulkError) Is(target error) bool {
+	if target == ErrBulk {
+		return true
+	}
+	return e.every(func(err error) bool { return errors.Is(err, target) })
+}
+
+// Unwrap returns the driver error that caused the error, or nil.
+func (e *basicQueryInfo) Unwrap() error {
+	return e.driverErr
+}
+
+func (e *mongoClientError) Unwrap() error {
+	return e.error
+}
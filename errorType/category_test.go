@@ -0,0 +1,93 @@
+package errorType
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// joinError is the shape of errors.Join of Go 1.20, which matches each of its errors.
+type joinError struct {
+	errs []error
+}
+
+func (e *joinError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+func Test_Categories(t *testing.T) {
+	cases := []struct {
+		err      error
+		category error
+	}{
+		{notFoundErr, ErrNotFound},
+		{dupKeyErr, ErrDuplicateKey},
+		{timeoutErr, ErrTimeout},
+		{decodeErr, ErrDecode},
+		{internalErr, ErrInternal},
+		{validateErr, ErrDocumentValidation},
+		{conflictErr, ErrVersionConflict},
+		{clientErr, ErrMongoClient},
+	}
+	for _, c := range cases {
+		assert.True(t, errors.Is(c.err, c.category), c.category.Error())
+		assert.True(t, errors.Is(errors.Wrap(c.err, "wrapped"), c.category), c.category.Error())
+		for _, other := range cases {
+			if other.category != c.category {
+				assert.False(t, errors.Is(c.err, other.category), c.category.Error()+" is "+other.category.Error())
+			}
+		}
+	}
+}
+
+func Test_Unwrap(t *testing.T) {
+	err := ParseAndReturnDBError(mongo.ErrNoDocuments, "col", nil, nil, nil)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, mongo.ErrNoDocuments))
+
+	err = ParseAndReturnDBError(context.DeadlineExceeded, "col", nil, nil, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	writeException := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	var cause mongo.WriteException
+	assert.True(t, errors.As(ParseAndReturnDBError(writeException, "col", nil, nil, nil), &cause))
+
+	assert.True(t, errors.Is(MongoClientError(context.Canceled), context.Canceled))
+}
+
+func Test_JoinedErrors(t *testing.T) {
+	err := &joinError{errs: []error{errors.New("other"), errors.Wrap(dupKeyErr, "insert")}}
+	assert.True(t, IsDuplicatedKeyErr(err))
+	assert.True(t, errors.Is(err, ErrDuplicateKey))
+	assert.False(t, IsNotFoundErr(err))
+
+	var queryErr QueryError
+	assert.True(t, errors.As(err, &queryErr))
+}
+
+func Test_BulkErrorCategories(t *testing.T) {
+	bulkException := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Index: 0, Code: 11000}}}}
+	err := ParseAndReturnBulkError(bulkException, "col", nil, nil)
+	assert.True(t, errors.Is(err, ErrBulk))
+	assert.True(t, errors.Is(err, ErrDuplicateKey))
+	assert.False(t, errors.Is(err, ErrDocumentValidation))
+
+	bulkException.WriteErrors = append(bulkException.WriteErrors, mongo.BulkWriteError{WriteError: mongo.WriteError{Index: 1, Code: 121}})
+	err = ParseAndReturnBulkError(bulkException, "col", nil, nil)
+	assert.True(t, errors.Is(err, ErrBulk))
+	assert.False(t, errors.Is(err, ErrDuplicateKey))
+	assert.False(t, IsDuplicatedKeyErr(err))
+	assert.False(t, errors.Is(err, ErrDocumentValidation))
+}
@@ -8,85 +8,81 @@ import (
 )
 
 func IsNotFoundErr(err error) bool {
-	for err != nil {
-		switch err.(type) {
-		case *notFoundError:
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	return errors.Is(err, ErrNotFound)
+}
+
+func IsVersionConflictErr(err error) bool {
+	return errors.Is(err, ErrVersionConflict)
 }
 
+// IsDuplicatedKeyErr reports whether err is a duplicate key error.
+// A BulkError is one only if all its failed models are; check each of its Errors for a partial failure.
 func IsDuplicatedKeyErr(err error) bool {
-	for err != nil {
-		switch err.(type) {
-		case *duplicatedKeyError:
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	return errors.Is(err, ErrDuplicateKey)
 }
 
 func IsTimeoutError(err error) bool {
-	for err != nil {
-		switch err.(type) {
-		case *timeoutError:
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	return errors.Is(err, ErrTimeout)
+}
+
+func IsDocumentValidationErr(err error) bool {
+	return errors.Is(err, ErrDocumentValidation)
 }
 
 func IsMongoClientError(err error) bool {
-	for err != nil {
-		switch err.(type) {
-		case *mongoClientError:
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	return errors.Is(err, ErrMongoClient)
+}
+
+// IsAuditErr reports whether the audit entry of a write in a transaction could not be written, which aborts the transaction.
+func IsAuditErr(err error) bool {
+	return errors.Is(err, ErrAudit)
 }
 
 func IsDBInternalErr(err error) bool {
-	for err != nil {
-		switch err.(type) {
-		case *internalError,
-			*timeoutError,
-			*mongoClientError:
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	var serverErr *serverError
+	return errors.Is(err, ErrInternal) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrMongoClient) || errors.As(err, &serverErr)
 }
 
 func IsDecodeError(err error) bool {
-	for err != nil {
-		switch err.(type) {
-		case *decodeError:
-			return true
-		}
-		err = errors.Unwrap(err)
-	}
-	return false
+	return errors.Is(err, ErrDecode)
 }
 
+// ParseAndReturnDBError returns err as the error of its category. An audit error is returned as it is.
 func ParseAndReturnDBError(err error, collection string, filter, update, doc interface{}) error {
+	if IsAuditErr(err) {
+		return err
+	}
+
 	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, NotMatchedAnyErr) {
-		return NotFoundError(collection, filter, update, doc)
+		return notFoundErrorOf(collection, filter, update, doc, err)
 	}
 
 	if mongo.IsDuplicateKeyError(err) {
 		return DuplicatedKeyError(collection, filter, update, doc, err)
 	}
 
+	if isDocumentValidationFailure(err) {
+		return DocumentValidationError(collection, filter, update, doc, err)
+	}
+
+	if category := serverErrorCategory(err); category != nil {
+		return ServerError(collection, filter, update, doc, category, err)
+	}
+
 	if mongo.IsTimeout(err) || errors.Is(err, context.DeadlineExceeded) {
 		return TimeoutError(collection, filter, update, doc, err)
 	}
 
 	return InternalError(collection, filter, update, doc, err)
 }
+
+// documentValidationFailureCode is returned by the server when a write violates the collection validator.
+const documentValidationFailureCode = 121
+
+func isDocumentValidationFailure(err error) bool {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.HasErrorCode(documentValidationFailureCode)
+	}
+	return false
+}
@@ -16,6 +16,8 @@ var (
 	internalErr = InternalError("col", nil, nil, nil, errors.New(""))
 	clientErr   = MongoClientError(errors.New(""))
 	timeoutErr  = TimeoutError("col", nil, nil, nil, errors.New(""))
+	validateErr = DocumentValidationError("col", nil, nil, nil, errors.New(""))
+	conflictErr = VersionConflictError("col", nil, nil, nil)
 )
 
 func Test_IsNotFoundErr(t *testing.T) {
@@ -93,6 +95,23 @@ func Test_IsDBInternalErr(t *testing.T) {
 	assert.False(t, result)
 }
 
+func Test_IsVersionConflictErr(t *testing.T) {
+	result := IsVersionConflictErr(conflictErr)
+	assert.True(t, result)
+	result = IsVersionConflictErr(errors.Wrap(conflictErr, ""))
+	assert.True(t, result)
+
+	result = IsVersionConflictErr(notFoundErr)
+	assert.False(t, result)
+	result = IsVersionConflictErr(dupKeyErr)
+	assert.False(t, result)
+	result = IsVersionConflictErr(internalErr)
+	assert.False(t, result)
+
+	result = IsNotFoundErr(conflictErr)
+	assert.False(t, result)
+}
+
 func Test_IsDuplicatedKeyErr(t *testing.T) {
 
 	result := IsDuplicatedKeyErr(dupKeyErr)
@@ -130,6 +149,22 @@ func Test_IsDecodeError(t *testing.T) {
 	assert.False(t, result)
 }
 
+func Test_IsDocumentValidationErr(t *testing.T) {
+	result := IsDocumentValidationErr(validateErr)
+	assert.True(t, result)
+	result = IsDocumentValidationErr(errors.Wrap(validateErr, ""))
+	assert.True(t, result)
+
+	result = IsDocumentValidationErr(notFoundErr)
+	assert.False(t, result)
+	result = IsDocumentValidationErr(dupKeyErr)
+	assert.False(t, result)
+	result = IsDocumentValidationErr(internalErr)
+	assert.False(t, result)
+	result = IsDocumentValidationErr(decodeErr)
+	assert.False(t, result)
+}
+
 func Test_ParseAndReturnDBError(t *testing.T) {
 	t.Run("not found", func(t *testing.T) {
 		err := mongo.ErrNoDocuments
@@ -155,6 +190,14 @@ func Test_ParseAndReturnDBError(t *testing.T) {
 		assert.IsType(t, &duplicatedKeyError{}, parsedErr)
 	})
 
+	t.Run("document validation", func(t *testing.T) {
+		err := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 121}}}
+
+		parsedErr := ParseAndReturnDBError(err, "", nil, nil, nil)
+		assert.Error(t, parsedErr)
+		assert.IsType(t, &documentValidationError{}, parsedErr)
+	})
+
 	t.Run("Timeout", func(t *testing.T) {
 		err := context.DeadlineExceeded
 
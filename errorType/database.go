@@ -2,6 +2,7 @@ package errorType
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/pkg/errors"
 )
@@ -9,13 +10,27 @@ import (
 var (
 	SingleResultErr  = errors.New("single result is nil")
 	NotMatchedAnyErr = errors.New("no documents have been matched")
+
+	SoftDeleteDisabledErr = errors.New("soft delete is not enabled on the collection")
+	AuditDisabledErr      = errors.New("audit is not enabled on the collection")
+	UpsertKeyMissingErr   = errors.New("upsert key field is missing in the document")
+	BulkWriterClosedErr   = errors.New("bulk writer is closed")
+	TransactionExistsErr  = errors.New("a transaction is running in the context")
+	RollbackOnlyErr       = errors.New("the transaction is rollback-only because a transaction that joined it failed")
+	PipelineScopeErr      = errors.New("the pipeline cannot be scoped to the documents that are not soft-deleted")
 )
 
 type basicQueryInfo struct {
+	category   error
 	collection string
 	filter     interface{}
 	update     interface{}
 	doc        interface{}
+	operation  string
+	write      bool
+	// documentType is the type of the documents of the collection, whose sensitive fields are redacted.
+	documentType reflect.Type
+	driverErr    error
 }
 
 type notFoundError struct {
@@ -24,24 +39,34 @@ type notFoundError struct {
 
 type duplicatedKeyError struct {
 	basicQueryInfo
-	error
 }
 
 type timeoutError struct {
 	basicQueryInfo
-	error
 }
 
 type internalError struct {
 	basicQueryInfo
-	error
+}
+
+type versionConflictError struct {
+	basicQueryInfo
+}
+
+type documentValidationError struct {
+	basicQueryInfo
+}
+
+type auditError struct {
+	basicQueryInfo
 }
 
 type mongoClientError struct {
 	error
 }
 
-func (err *basicQueryInfo) setBasicError(col string, filter, update, doc interface{}) {
+func (err *basicQueryInfo) setBasicError(category error, col string, filter, update, doc interface{}) {
+	err.category = category
 	err.filter = filter
 	err.collection = col
 	err.update = update
@@ -49,29 +74,57 @@ func (err *basicQueryInfo) setBasicError(col string, filter, update, doc interfa
 }
 
 func NotFoundError(col string, filter, update, doc interface{}) error {
+	return notFoundErrorOf(col, filter, update, doc, nil)
+}
+
+// notFoundErrorOf returns notFoundError that keeps the error that caused it, such as mongo.ErrNoDocuments.
+func notFoundErrorOf(col string, filter, update, doc interface{}, cause error) error {
 	err := &notFoundError{}
-	err.setBasicError(col, filter, update, doc)
+	err.setBasicError(ErrNotFound, col, filter, update, doc)
+	err.driverErr = cause
+	return err
+}
+
+func VersionConflictError(col string, filter, update, doc interface{}) error {
+	err := &versionConflictError{}
+	err.setBasicError(ErrVersionConflict, col, filter, update, doc)
 	return err
 }
 
 func DuplicatedKeyError(col string, filter, update, doc interface{}, mongoErr error) error {
 	err := &duplicatedKeyError{}
-	err.setBasicError(col, filter, update, doc)
-	err.error = mongoErr
+	err.setBasicError(ErrDuplicateKey, col, filter, update, doc)
+	err.driverErr = mongoErr
 	return err
 }
 
 func TimeoutError(col string, filter, update, doc interface{}, mongoErr error) error {
 	err := &timeoutError{}
-	err.setBasicError(col, filter, update, doc)
-	err.error = mongoErr
+	err.setBasicError(ErrTimeout, col, filter, update, doc)
+	err.driverErr = mongoErr
 	return err
 }
 
 func InternalError(col string, filter, update, doc interface{}, mongoErr error) error {
 	err := &internalError{}
-	err.setBasicError(col, filter, update, doc)
-	err.error = mongoErr
+	err.setBasicError(ErrInternal, col, filter, update, doc)
+	err.driverErr = mongoErr
+	return err
+}
+
+func DocumentValidationError(col string, filter, update, doc interface{}, mongoErr error) error {
+	err := &documentValidationError{}
+	err.setBasicError(ErrDocumentValidation, col, filter, update, doc)
+	err.driverErr = mongoErr
+	return err
+}
+
+// AuditError returns the error of an audit entry that could not be written to the audit collection col.
+// It is returned in a transaction only, so that the audited write is rolled back with the transaction.
+func AuditError(col string, mongoErr error) error {
+	err := &auditError{}
+	err.setBasicError(ErrAudit, col, nil, nil, nil)
+	err.driverErr = mongoErr
 	return err
 }
 
@@ -83,16 +136,28 @@ func (e *notFoundError) Error() string {
 	return fmt.Sprintf("%s not found. ", e.collection) + getBasicInfoErrorMsg(e.basicQueryInfo)
 }
 
+func (e *versionConflictError) Error() string {
+	return fmt.Sprintf("%s version conflict, the document was modified concurrently. ", e.collection) + getBasicInfoErrorMsg(e.basicQueryInfo)
+}
+
 func (e *duplicatedKeyError) Error() string {
-	return fmt.Sprintf("%s failed to write due to duplicated key, err: %s ", e.collection, e.error.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+	return fmt.Sprintf("%s failed to write due to duplicated key, err: %s ", e.collection, e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
 }
 
 func (e *timeoutError) Error() string {
-	return fmt.Sprintf("%s timeout, err: %s ", e.collection, e.error.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+	return fmt.Sprintf("%s timeout, err: %s ", e.collection, e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
 }
 
 func (e *internalError) Error() string {
-	return fmt.Sprintf("mongo internal err: %s ", e.error.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+	return fmt.Sprintf("mongo internal err: %s ", e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+}
+
+func (e *documentValidationError) Error() string {
+	return fmt.Sprintf("%s failed document validation, err: %s ", e.collection, e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+}
+
+func (e *auditError) Error() string {
+	return fmt.Sprintf("%s failed to write the audit entry, err: %s ", e.collection, e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
 }
 
 func (e *mongoClientError) Error() string {
@@ -102,15 +167,15 @@ func (e *mongoClientError) Error() string {
 func getBasicInfoErrorMsg(e basicQueryInfo) string {
 	msg := "| {query info: "
 	if e.filter != nil {
-		msg += fmt.Sprintf(" filter: %+v", e.filter)
+		msg += fmt.Sprintf(" filter: %s", redact(e.filter, nil, e.documentType, false))
 	}
 
 	if e.update != nil {
-		msg += fmt.Sprintf(", update: %+v", e.update)
+		msg += fmt.Sprintf(", update: %s", redact(e.update, nil, e.documentType, false))
 	}
 
 	if e.doc != nil {
-		msg += fmt.Sprintf(", doc: %+v", e.doc)
+		msg += fmt.Sprintf(", doc: %s", redact(e.doc, nil, e.documentType, false))
 	}
 	msg += "}"
 	return msg
@@ -4,16 +4,15 @@ import "fmt"
 
 type decodeError struct {
 	basicQueryInfo
-	error
 }
 
 func DecodeError(col string, filter, update, doc interface{}, mongoErr error) error {
 	err := &decodeError{}
-	err.setBasicError(col, filter, update, doc)
-	err.error = mongoErr
+	err.setBasicError(ErrDecode, col, filter, update, doc)
+	err.driverErr = mongoErr
 	return err
 }
 
-func (e decodeError) Error() string {
-	return fmt.Sprintf("decode document err: %s ", e.error.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("decode document err: %s ", e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
 }
@@ -0,0 +1,121 @@
+package errorType
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Keys of the fields of an error, in the order they are logged.
+const (
+	FieldCategory   = "category"
+	FieldCollection = "collection"
+	FieldOperation  = "operation"
+	FieldFilter     = "filter"
+	FieldUpdate     = "update"
+	FieldDoc        = "doc"
+	FieldCode       = "code"
+	FieldError      = "error"
+	FieldErrors     = "errors"
+	FieldIndex      = "index"
+)
+
+var fieldOrder = []string{FieldCategory, FieldCollection, FieldOperation, FieldFilter, FieldUpdate, FieldDoc, FieldCode, FieldError, FieldErrors, FieldIndex}
+
+// fielder is implemented by the errors of this package.
+type fielder interface {
+	Fields() map[string]interface{}
+}
+
+// Fields returns the fields of the first error of this package in err for structured logging, or nil if there is none.
+//
+//	logger.Error("failed to find account", zap.Any("mongo", errorType.Fields(err)))
+func Fields(err error) map[string]interface{} {
+	var f fielder
+	if errors.As(err, &f) {
+		return f.Fields()
+	}
+	return nil
+}
+
+// Fields returns the category, collection, operation, the filter, update and doc as redacted extended JSON,
+// the server error code and the driver error. Empty fields are omitted.
+func (e *basicQueryInfo) Fields() map[string]interface{} {
+	fields := map[string]interface{}{FieldCollection: e.collection}
+	if e.category != nil {
+		fields[FieldCategory] = e.category.Error()
+	}
+	if e.operation != "" {
+		fields[FieldOperation] = e.operation
+	}
+	if e.filter != nil {
+		fields[FieldFilter] = redact(e.filter, nil, e.documentType, true)
+	}
+	if e.update != nil {
+		fields[FieldUpdate] = redact(e.update, nil, e.documentType, true)
+	}
+	if e.doc != nil {
+		fields[FieldDoc] = redact(e.doc, nil, e.documentType, true)
+	}
+	if code := e.Code(); code != 0 {
+		fields[FieldCode] = code
+	}
+	if e.driverErr != nil {
+		fields[FieldError] = e.driverErr.Error()
+	}
+	return fields
+}
+
+func (e *basicQueryInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Fields())
+}
+
+func (e *mongoClientError) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		FieldCategory: ErrMongoClient.Error(),
+		FieldError:    e.error.Error(),
+	}
+}
+
+func (e *mongoClientError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Fields())
+}
+
+// Fields returns the fields of the bulk error, with the fields and index of each failed model in "errors".
+func (e *BulkError) Fields() map[string]interface{} {
+	writeErrs := make([]map[string]interface{}, 0, len(e.Errors))
+	for _, writeErr := range e.Errors {
+		fields := Fields(writeErr.Err)
+		if fields == nil {
+			fields = map[string]interface{}{FieldError: writeErr.Err.Error()}
+		}
+		fields[FieldIndex] = writeErr.Index
+		writeErrs = append(writeErrs, fields)
+	}
+	fields := map[string]interface{}{
+		FieldCategory:   ErrBulk.Error(),
+		FieldCollection: e.Collection,
+		FieldErrors:     writeErrs,
+	}
+	if e.WriteConcernError != nil {
+		fields[FieldError] = e.WriteConcernError.Error()
+	}
+	return fields
+}
+
+func (e *BulkError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Fields())
+}
+
+func (e *transactionError) Fields() map[string]interface{} {
+	fields := Fields(e.driverErr)
+	if fields == nil {
+		fields = map[string]interface{}{FieldError: e.driverErr.Error()}
+	}
+	fields[FieldCategory] = e.category.Error()
+	return fields
+}
+
+func (e *transactionError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Fields())
+}
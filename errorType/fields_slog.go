@@ -0,0 +1,46 @@
+//go:build go1.21
+
+package errorType
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+// LogValue implements slog.LogValuer with the fields of the error.
+//
+//	slog.Error("failed to find account", "err", err)
+func (e *basicQueryInfo) LogValue() slog.Value {
+	return logValueOf(e.Fields())
+}
+
+func (e *mongoClientError) LogValue() slog.Value {
+	return logValueOf(e.Fields())
+}
+
+func (e *BulkError) LogValue() slog.Value {
+	return logValueOf(e.Fields())
+}
+
+func (e *transactionError) LogValue() slog.Value {
+	return logValueOf(e.Fields())
+}
+
+func logValueOf(fields map[string]interface{}) slog.Value {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, key := range fieldOrder {
+		value, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if writeErrs, ok := value.([]map[string]interface{}); ok {
+			group := make([]slog.Attr, len(writeErrs))
+			for i, writeErr := range writeErrs {
+				group[i] = slog.Attr{Key: strconv.Itoa(i), Value: logValueOf(writeErr)}
+			}
+			value = slog.GroupValue(group...)
+		}
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	return slog.GroupValue(attrs...)
+}
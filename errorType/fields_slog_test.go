@@ -0,0 +1,46 @@
+//go:build go1.21
+
+package errorType
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	err := WithOperation(NotFoundError("accounts", bson.M{"account_id": 1}, nil, nil), "FindOne")
+	bulkErr := &BulkError{Collection: "accounts", Errors: []BulkWriteError{{Index: 2, Err: err}}}
+	logger.Error("failed", "err", err, "bulk", bulkErr, "client", MongoClientError(errors.New("closed")))
+
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, map[string]interface{}{
+		FieldCategory:   "not found",
+		FieldCollection: "accounts",
+		FieldOperation:  "FindOne",
+		FieldFilter:     `{"account_id": {"$numberInt":"1"}}`,
+	}, record["err"])
+	assert.Equal(t, map[string]interface{}{
+		FieldCategory:   "bulk write",
+		FieldCollection: "accounts",
+		FieldErrors: map[string]interface{}{
+			"0": map[string]interface{}{
+				FieldCategory:   "not found",
+				FieldCollection: "accounts",
+				FieldOperation:  "FindOne",
+				FieldFilter:     `{"account_id": {"$numberInt":"1"}}`,
+				FieldIndex:      float64(2),
+			},
+		},
+	}, record["bulk"])
+	assert.Equal(t, map[string]interface{}{FieldCategory: "mongo client", FieldError: "closed"}, record["client"])
+}
@@ -0,0 +1,64 @@
+package errorType
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_Fields(t *testing.T) {
+	driverErr := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+	err := WithOperation(ParseAndReturnDBError(driverErr, "accounts", bson.M{"account_id": 1}, nil, nil), "InsertOne")
+
+	expected := map[string]interface{}{
+		FieldCategory:   "duplicate key",
+		FieldCollection: "accounts",
+		FieldOperation:  "InsertOne",
+		FieldFilter:     `{"account_id": {"$numberInt":"1"}}`,
+		FieldCode:       11000,
+		FieldError:      driverErr.Error(),
+	}
+	assert.Equal(t, expected, Fields(errors.Wrap(err, "insert")))
+
+	data, jsonErr := json.Marshal(err)
+	assert.Nil(t, jsonErr)
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "duplicate key", decoded[FieldCategory])
+	assert.Equal(t, float64(11000), decoded[FieldCode])
+
+	assert.Equal(t, map[string]interface{}{FieldCategory: "not found", FieldCollection: "col"}, Fields(notFoundErr))
+	assert.Equal(t, map[string]interface{}{FieldCategory: "write conflict", FieldCollection: "col", FieldCode: 112, FieldError: mongo.CommandError{Code: 112}.Error()},
+		Fields(ParseAndReturnDBError(mongo.CommandError{Code: 112}, "col", nil, nil, nil)))
+	assert.Equal(t, "mongo client", Fields(clientErr)[FieldCategory])
+	assert.Nil(t, Fields(errors.New("other")))
+}
+
+func Test_FieldsRedacted(t *testing.T) {
+	defer SetRedactionPolicy(nil)
+	SetRedactionPolicy(&RedactionPolicy{DenyFields: []string{"email"}})
+
+	fields := Fields(NotFoundError("accounts", bson.M{"email": "kim@example.com"}, nil, nil))
+	assert.Equal(t, `{"email": "[REDACTED]"}`, fields[FieldFilter])
+}
+
+func Test_BulkErrorFields(t *testing.T) {
+	bulkErr := &BulkError{
+		Collection: "accounts",
+		Errors: []BulkWriteError{
+			{Index: 1, Err: DuplicatedKeyError("accounts", nil, nil, nil, errors.New("E11000"))},
+			{Index: 3, Err: errors.New("other")},
+		},
+	}
+	fields := Fields(bulkErr)
+	assert.Equal(t, "bulk write", fields[FieldCategory])
+	assert.Equal(t, "accounts", fields[FieldCollection])
+	assert.Equal(t, []map[string]interface{}{
+		{FieldCategory: "duplicate key", FieldCollection: "accounts", FieldError: "E11000", FieldIndex: 1},
+		{FieldError: "other", FieldIndex: 3},
+	}, fields[FieldErrors])
+}
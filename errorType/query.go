@@ -0,0 +1,171 @@
+package errorType
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// QueryError is implemented by the errors of query functions. Use it with errors.As to inspect an error.
+//
+//	var queryErr errorType.QueryError
+//	if errors.As(err, &queryErr) {
+//		log.Println(queryErr.Operation(), queryErr.Collection(), queryErr.Code())
+//	}
+type QueryError interface {
+	error
+	// Collection returns the name of the collection of the query.
+	Collection() string
+	Filter() interface{}
+	Update() interface{}
+	Doc() interface{}
+	// Operation returns the name of the wrapper function that returned the error, such as "FindOne".
+	Operation() string
+	// DriverError returns the error of the driver that caused the error, or nil.
+	DriverError() error
+	// Code returns the server error code of the driver error, or 0.
+	Code() int
+
+	setOperation(operation string)
+	setWrite()
+	isWrite() bool
+	setDocumentType(documentType reflect.Type)
+}
+
+func (e *basicQueryInfo) Collection() string {
+	return e.collection
+}
+
+func (e *basicQueryInfo) Filter() interface{} {
+	return e.filter
+}
+
+func (e *basicQueryInfo) Update() interface{} {
+	return e.update
+}
+
+func (e *basicQueryInfo) Doc() interface{} {
+	return e.doc
+}
+
+func (e *basicQueryInfo) Operation() string {
+	return e.operation
+}
+
+func (e *basicQueryInfo) DriverError() error {
+	return e.driverErr
+}
+
+func (e *basicQueryInfo) Code() int {
+	return serverErrorCode(e.driverErr)
+}
+
+func (e *basicQueryInfo) setOperation(operation string) {
+	e.operation = operation
+}
+
+func (e *basicQueryInfo) setWrite() {
+	e.write = true
+}
+
+func (e *basicQueryInfo) isWrite() bool {
+	return e.write
+}
+
+func (e *basicQueryInfo) setDocumentType(documentType reflect.Type) {
+	e.documentType = documentType
+}
+
+// WithOperation sets the operation of a QueryError, and of each error of a BulkError, and returns err.
+// Other errors are returned as they are.
+func WithOperation(err error, operation string) error {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		for _, writeErr := range bulkErr.Errors {
+			_ = WithOperation(writeErr.Err, operation)
+		}
+		return err
+	}
+	var queryErr QueryError
+	if errors.As(err, &queryErr) {
+		queryErr.setOperation(operation)
+	}
+	return err
+}
+
+// WithDocumentType sets the type of the documents of the collection on a QueryError, and on each error of a BulkError,
+// and returns err. The fields tagged `mongo:"sensitive"` in the type are redacted in its filter, update and doc,
+// even if they are not of the type, such as a bson.M filter.
+func WithDocumentType(err error, documentType reflect.Type) error {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		for _, writeErr := range bulkErr.Errors {
+			_ = WithDocumentType(writeErr.Err, documentType)
+		}
+		return err
+	}
+	var queryErr QueryError
+	if errors.As(err, &queryErr) {
+		queryErr.setDocumentType(documentType)
+	}
+	return err
+}
+
+// WithWriteOperation is WithOperation for the operations that write. IsRetryable retries the errors of a write
+// only if the write was not applied, and IsOutcomeUnknown reports the errors after which it may have been.
+func WithWriteOperation(err error, operation string) error {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		for _, writeErr := range bulkErr.Errors {
+			_ = WithWriteOperation(writeErr.Err, operation)
+		}
+		return err
+	}
+	var queryErr QueryError
+	if errors.As(err, &queryErr) {
+		queryErr.setWrite()
+	}
+	return WithOperation(err, operation)
+}
+
+// isWrite reports whether err is of an operation that writes. A BulkError always is.
+func isWrite(err error) bool {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		return true
+	}
+	var queryErr QueryError
+	return errors.As(err, &queryErr) && queryErr.isWrite()
+}
+
+// serverErrorCode returns the code of the first server error in err, or 0.
+func serverErrorCode(err error) int {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return int(commandErr.Code)
+	}
+	var writeException mongo.WriteException
+	if errors.As(err, &writeException) {
+		if len(writeException.WriteErrors) > 0 {
+			return writeException.WriteErrors[0].Code
+		}
+		if writeException.WriteConcernError != nil {
+			return writeException.WriteConcernError.Code
+		}
+	}
+	var bulkException mongo.BulkWriteException
+	if errors.As(err, &bulkException) {
+		if len(bulkException.WriteErrors) > 0 {
+			return bulkException.WriteErrors[0].Code
+		}
+		if bulkException.WriteConcernError != nil {
+			return bulkException.WriteConcernError.Code
+		}
+	}
+	var writeErr mongo.WriteError
+	if errors.As(err, &writeErr) {
+		return writeErr.Code
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package errorType
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_QueryError(t *testing.T) {
+	driverErr := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+	err := WithOperation(ParseAndReturnDBError(driverErr, "col", map[string]int{"a": 1}, nil, "doc"), "InsertOne")
+
+	var queryErr QueryError
+	assert.True(t, errors.As(errors.Wrap(err, ""), &queryErr))
+	assert.True(t, IsDuplicatedKeyErr(queryErr))
+	assert.Equal(t, "col", queryErr.Collection())
+	assert.Equal(t, map[string]int{"a": 1}, queryErr.Filter())
+	assert.Nil(t, queryErr.Update())
+	assert.Equal(t, "doc", queryErr.Doc())
+	assert.Equal(t, "InsertOne", queryErr.Operation())
+	assert.Equal(t, driverErr, queryErr.DriverError())
+	assert.Equal(t, 11000, queryErr.Code())
+
+	assert.True(t, errors.As(notFoundErr, &queryErr))
+	assert.Nil(t, queryErr.DriverError())
+	assert.Equal(t, 0, queryErr.Code())
+
+	assert.False(t, errors.As(clientErr, &queryErr))
+	assert.Nil(t, WithOperation(nil, "FindOne"))
+}
+
+func Test_serverErrorCode(t *testing.T) {
+	assert.Equal(t, 50, serverErrorCode(mongo.CommandError{Code: 50}))
+	assert.Equal(t, 64, serverErrorCode(mongo.WriteException{WriteConcernError: &mongo.WriteConcernError{Code: 64}}))
+	assert.Equal(t, 121, serverErrorCode(mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 121}}}}))
+	assert.Equal(t, 0, serverErrorCode(errors.New("test")))
+	assert.Equal(t, 0, serverErrorCode(nil))
+}
+
+func Test_WithOperation_BulkError(t *testing.T) {
+	bulkException := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Index: 0, Code: 11000}}}}
+	err := WithOperation(ParseAndReturnBulkError(bulkException, "col", nil, nil), "BulkWrite")
+
+	var queryErr QueryError
+	assert.True(t, errors.As(err.(*BulkError).Failed(0), &queryErr))
+	assert.Equal(t, "BulkWrite", queryErr.Operation())
+}
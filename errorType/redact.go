@@ -0,0 +1,264 @@
+package errorType
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+)
+
+const (
+	redacted           = "[REDACTED]"
+	sensitiveTagOption = "sensitive"
+)
+
+// RedactionPolicy decides how filters, updates and documents are printed in error messages and slow query logs.
+// Fields of structs tagged `mongo:"sensitive"` are always redacted.
+type RedactionPolicy struct {
+	// If not empty, only the values of these fields are printed and the others are redacted.
+	AllowFields []string
+
+	// The values of these fields are redacted. A field is a name at any depth, such as "email", or a dotted path, such as "user.email".
+	DenyFields []string
+
+	// If true, a redacted value is replaced by a hash of it instead of "[REDACTED]", so that equal values can be told apart.
+	HashValues bool
+
+	// The maximum length of a printed value. Longer values are truncated. 0 means no limit.
+	MaxLength int
+}
+
+var (
+	redactionPolicyMu sync.RWMutex
+	redactionPolicy   *RedactionPolicy
+)
+
+// SetRedactionPolicy sets the policy of Redact. nil removes it.
+func SetRedactionPolicy(policy *RedactionPolicy) {
+	redactionPolicyMu.Lock()
+	defer redactionPolicyMu.Unlock()
+	redactionPolicy = policy
+}
+
+func currentRedactionPolicy() *RedactionPolicy {
+	redactionPolicyMu.RLock()
+	defer redactionPolicyMu.RUnlock()
+	return redactionPolicy
+}
+
+// Redact returns v formatted for error messages and logs with the redaction policy applied.
+// v is printed as extended JSON if a policy is set or v has sensitive fields, and with %+v otherwise.
+func Redact(v interface{}) string {
+	return redact(v, nil, nil, false)
+}
+
+// RedactJSON is Redact that always prints v as extended JSON.
+func RedactJSON(v interface{}) string {
+	return redact(v, nil, nil, true)
+}
+
+// RedactFrom is Redact that also redacts the fields tagged `mongo:"sensitive"` in source, such as the struct
+// that v was converted from.
+func RedactFrom(v, source interface{}) string {
+	return redact(v, source, nil, false)
+}
+
+// RedactAs is RedactFrom that also redacts the fields tagged `mongo:"sensitive"` in documentType, the type of the
+// documents of the collection, so that they are redacted in filters and updates such as bson.M as well.
+func RedactAs(v, source interface{}, documentType reflect.Type) string {
+	return redact(v, source, documentType, false)
+}
+
+func redact(v, source interface{}, documentType reflect.Type, extJSON bool) string {
+	policy := currentRedactionPolicy()
+	sensitive := sensitiveFieldsOf(v)
+	for name := range sensitiveFieldsOf(source) {
+		sensitive[name] = true
+	}
+	for documentType != nil && documentType.Kind() == reflect.Pointer {
+		documentType = documentType.Elem()
+	}
+	if documentType != nil && documentType.Kind() == reflect.Struct {
+		for name := range sensitiveFieldsOfType(documentType) {
+			sensitive[name] = true
+		}
+	}
+	if !extJSON && policy == nil && len(sensitive) == 0 {
+		return fmt.Sprintf("%+v", v)
+	}
+	if policy == nil {
+		policy = &RedactionPolicy{}
+	}
+
+	valueType, data, err := bson.MarshalValue(v)
+	if err != nil {
+		return redacted
+	}
+	var value interface{}
+	if err := (bson.RawValue{Type: valueType, Value: data}).Unmarshal(&value); err != nil {
+		return redacted
+	}
+	r := redactor{policy: policy, sensitive: sensitive}
+	return policy.truncate(extJSONOf(r.redact(value, "", false)))
+}
+
+type redactor struct {
+	policy    *RedactionPolicy
+	sensitive map[string]bool
+}
+
+// redact returns a copy of value with the values of redacted fields replaced. Operators such as $set are not fields.
+func (r redactor) redact(value interface{}, path string, allowed bool) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		result := make(bson.D, 0, len(v))
+		for _, element := range v {
+			if strings.HasPrefix(element.Key, "$") {
+				result = append(result, bson.E{Key: element.Key, Value: r.redact(element.Value, path, allowed)})
+				continue
+			}
+			fieldPath := element.Key
+			if path != "" {
+				fieldPath = path + "." + element.Key
+			}
+			switch {
+			case r.denied(element.Key, fieldPath):
+				result = append(result, bson.E{Key: element.Key, Value: r.mask(element.Value)})
+			case allowed || r.allowed(element.Key, fieldPath):
+				result = append(result, bson.E{Key: element.Key, Value: r.redact(element.Value, fieldPath, true)})
+			default:
+				result = append(result, bson.E{Key: element.Key, Value: r.redact(element.Value, fieldPath, false)})
+			}
+		}
+		return result
+	case bson.A:
+		result := make(bson.A, len(v))
+		for i, item := range v {
+			result[i] = r.redact(item, path, allowed)
+		}
+		return result
+	}
+	if allowed || len(r.policy.AllowFields) == 0 || path == "" {
+		return value
+	}
+	return r.mask(value)
+}
+
+func (r redactor) denied(name, path string) bool {
+	return r.sensitive[name] || matchField(r.policy.DenyFields, name, path)
+}
+
+func (r redactor) allowed(name, path string) bool {
+	return len(r.policy.AllowFields) == 0 || matchField(r.policy.AllowFields, name, path)
+}
+
+func (r redactor) mask(value interface{}) interface{} {
+	if !r.policy.HashValues {
+		return redacted
+	}
+	sum := sha256.Sum256([]byte(extJSONOf(value)))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// truncate cuts s to MaxLength bytes, or fewer so that a character is not split.
+func (p *RedactionPolicy) truncate(s string) string {
+	if p.MaxLength <= 0 || len(s) <= p.MaxLength {
+		return s
+	}
+	length := p.MaxLength
+	for length > 0 && !utf8.RuneStart(s[length]) {
+		length--
+	}
+	return s[:length] + fmt.Sprintf("...(%d bytes truncated)", len(s)-length)
+}
+
+func matchField(fields []string, name, path string) bool {
+	for _, field := range fields {
+		if field == name || field == path {
+			return true
+		}
+	}
+	return false
+}
+
+func extJSONOf(v interface{}) string {
+	valueType, data, err := bson.MarshalValue(v)
+	if err != nil {
+		return redacted
+	}
+	return bson.RawValue{Type: valueType, Value: data}.String()
+}
+
+var sensitiveFieldsCache sync.Map
+
+// sensitiveFieldsOf returns the bson names of the fields tagged `mongo:"sensitive"` in the structs that v holds.
+func sensitiveFieldsOf(v interface{}) map[string]bool {
+	fields := map[string]bool{}
+	collectSensitiveFields(reflect.ValueOf(v), fields, 0)
+	return fields
+}
+
+const maxSensitiveFieldsDepth = 32
+
+func collectSensitiveFields(v reflect.Value, fields map[string]bool, depth int) {
+	if !v.IsValid() || depth > maxSensitiveFieldsDepth {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectSensitiveFields(v.Elem(), fields, depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			collectSensitiveFields(v.Index(i), fields, depth+1)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectSensitiveFields(iter.Value(), fields, depth+1)
+		}
+	case reflect.Struct:
+		for name := range sensitiveFieldsOfType(v.Type()) {
+			fields[name] = true
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectSensitiveFields(v.Field(i), fields, depth+1)
+			}
+		}
+	}
+}
+
+func sensitiveFieldsOfType(t reflect.Type) map[string]bool {
+	if cached, ok := sensitiveFieldsCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tags, err := bsoncodec.DefaultStructTagParser(field)
+		if err != nil || tags.Skip {
+			continue
+		}
+		for _, option := range strings.Split(field.Tag.Get("mongo"), ",") {
+			if option == sensitiveTagOption {
+				fields[tags.Name] = true
+			}
+		}
+	}
+	sensitiveFieldsCache.Store(t, fields)
+	return fields
+}
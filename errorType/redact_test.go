@@ -0,0 +1,130 @@
+package errorType
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type credential struct {
+	Name     string `bson:"name"`
+	Email    string `bson:"email" mongo:"sensitive"`
+	Token    string `bson:"token,omitempty" mongo:"sensitive"`
+	Disabled bool   `bson:"disabled"`
+}
+
+func Test_Redact(t *testing.T) {
+	defer SetRedactionPolicy(nil)
+
+	filter := bson.M{"account_id": 1}
+	assert.Equal(t, "map[account_id:1]", Redact(filter))
+
+	doc := credential{Name: "kim", Email: "kim@example.com", Token: "secret"}
+	assert.Equal(t, `{"name": "kim","email": "[REDACTED]","token": "[REDACTED]","disabled": false}`, Redact(doc))
+	assert.Equal(t, `[{"name": "kim","email": "[REDACTED]","token": "[REDACTED]","disabled": false}]`, Redact([]interface{}{&doc}))
+
+	cases := []struct {
+		name     string
+		policy   *RedactionPolicy
+		value    interface{}
+		expected string
+	}{
+		{
+			"deny field",
+			&RedactionPolicy{DenyFields: []string{"email"}},
+			bson.D{{Key: "name", Value: "kim"}, {Key: "user", Value: bson.D{{Key: "email", Value: "kim@example.com"}}}},
+			`{"name": "kim","user": {"email": "[REDACTED]"}}`,
+		},
+		{
+			"deny path",
+			&RedactionPolicy{DenyFields: []string{"user.email"}},
+			bson.D{{Key: "email", Value: "a"}, {Key: "user", Value: bson.D{{Key: "email", Value: "b"}}}},
+			`{"email": "a","user": {"email": "[REDACTED]"}}`,
+		},
+		{
+			"operators",
+			&RedactionPolicy{DenyFields: []string{"email"}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "email", Value: "a"}}}, {Key: "$or", Value: bson.A{bson.D{{Key: "email", Value: "b"}}}}},
+			`{"$set": {"email": "[REDACTED]"},"$or": [{"email": "[REDACTED]"}]}`,
+		},
+		{
+			"allow fields",
+			&RedactionPolicy{AllowFields: []string{"name", "user"}},
+			bson.D{{Key: "name", Value: "kim"}, {Key: "phone", Value: "010"}, {Key: "user", Value: bson.D{{Key: "id", Value: 1}}}},
+			`{"name": "kim","phone": "[REDACTED]","user": {"id": {"$numberInt":"1"}}}`,
+		},
+		{
+			"hash values",
+			&RedactionPolicy{DenyFields: []string{"email"}, HashValues: true},
+			bson.D{{Key: "email", Value: "a"}, {Key: "other", Value: bson.D{{Key: "email", Value: "a"}}}},
+			`{"email": "sha256:` + hashOf("a") + `","other": {"email": "sha256:` + hashOf("a") + `"}}`,
+		},
+		{
+			"max length",
+			&RedactionPolicy{MaxLength: 10},
+			bson.D{{Key: "name", Value: "kim"}, {Key: "email", Value: "kim@example.com"}},
+			`{"name": "...(32 bytes truncated)`,
+		},
+		{
+			"max length in a character",
+			&RedactionPolicy{MaxLength: 12},
+			bson.D{{Key: "name", Value: "김민수"}},
+			`{"name": "...(11 bytes truncated)`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetRedactionPolicy(c.policy)
+			assert.Equal(t, c.expected, Redact(c.value))
+		})
+	}
+}
+
+func Test_RedactFrom(t *testing.T) {
+	doc := bson.D{{Key: "name", Value: "kim"}, {Key: "email", Value: "kim@example.com"}, {Key: "created", Value: 1}}
+	assert.Equal(t, `{"name": "kim","email": "[REDACTED]","created": {"$numberInt":"1"}}`, RedactFrom(doc, credential{}))
+	assert.Equal(t, Redact(doc), RedactFrom(doc, nil))
+}
+
+func Test_RedactAs(t *testing.T) {
+	filter := bson.M{"email": "kim@example.com"}
+	assert.Equal(t, `{"email": "[REDACTED]"}`, RedactAs(filter, nil, reflect.TypeOf(credential{})))
+	assert.Equal(t, `{"email": "[REDACTED]"}`, RedactAs(filter, nil, reflect.TypeOf(&credential{})))
+	assert.Equal(t, Redact(filter), RedactAs(filter, nil, nil))
+	assert.Equal(t, Redact(filter), RedactAs(filter, nil, reflect.TypeOf(bson.M{})))
+}
+
+func Test_WithDocumentType(t *testing.T) {
+	filter := bson.M{"email": "kim@example.com"}
+	update := bson.M{"$set": bson.M{"token": "secret"}}
+	err := WithDocumentType(NotFoundError("accounts", filter, update, nil), reflect.TypeOf(credential{}))
+	assert.NotContains(t, err.Error(), "kim@example.com")
+	assert.NotContains(t, err.Error(), "secret")
+	fields := Fields(err)
+	assert.Equal(t, `{"email": "[REDACTED]"}`, fields[FieldFilter])
+	assert.Equal(t, `{"$set": {"token": "[REDACTED]"}}`, fields[FieldUpdate])
+	assert.Equal(t, filter, err.(QueryError).Filter())
+
+	bulkErr := ParseAndReturnBulkError(mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 0, Code: 11000}},
+	}}, "accounts", nil, []interface{}{bson.M{"email": "kim@example.com"}})
+	err = WithDocumentType(bulkErr, reflect.TypeOf(credential{}))
+	assert.NotContains(t, err.Error(), "kim@example.com")
+}
+
+func Test_RedactErrorMessage(t *testing.T) {
+	defer SetRedactionPolicy(nil)
+	SetRedactionPolicy(&RedactionPolicy{DenyFields: []string{"email"}})
+
+	err := NotFoundError("accounts", bson.M{"email": "kim@example.com"}, nil, credential{Email: "kim@example.com"})
+	assert.False(t, strings.Contains(err.Error(), "kim@example.com"))
+	assert.Equal(t, bson.M{"email": "kim@example.com"}, err.(QueryError).Filter())
+}
+
+func hashOf(s string) string {
+	return strings.TrimPrefix(redactor{policy: &RedactionPolicy{HashValues: true}}.mask(s).(string), "sha256:")
+}
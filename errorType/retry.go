@@ -0,0 +1,125 @@
+package errorType
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	retryableWriteErrorLabel            = "RetryableWriteError"
+	transientTransactionErrorLabel      = "TransientTransactionError"
+	unknownTransactionCommitResultLabel = "UnknownTransactionCommitResult"
+)
+
+// retryableCodes are the server error codes that the drivers retry reads and writes on.
+var retryableCodes = []int{6, 7, 89, 91, 134, 189, 262, 9001, 10107, 11600, 11602, 13435, 13436}
+
+// retryAfters are the hints of RetryAfter, in the order they are checked.
+var retryAfters = []struct {
+	category   error
+	retryAfter time.Duration
+}{
+	{ErrWriteConflict, 10 * time.Millisecond},
+	{ErrStaleConfig, 100 * time.Millisecond},
+	{ErrNetwork, 100 * time.Millisecond},
+	{ErrNotPrimary, 500 * time.Millisecond},
+	{ErrInterrupted, 500 * time.Millisecond},
+	{ErrTimeout, time.Second},
+}
+
+const defaultRetryAfter = 50 * time.Millisecond
+
+// IsTransient reports whether err is caused by a temporary state of the cluster, such as an election,
+// a network failure or a conflict with another transaction. The whole transaction can be retried on it.
+// An audit error is transient, because it is only returned in a transaction, which it aborts with the audited write.
+// An unknown commit result is not, because the transaction may have been committed. See IsCommitRetryable for it.
+func IsTransient(err error) bool {
+	if err == nil || IsCommitRetryable(err) {
+		return false
+	}
+	if IsAuditErr(err) || hasErrorLabel(err, transientTransactionErrorLabel) || mongo.IsNetworkError(err) {
+		return true
+	}
+	for _, category := range []error{ErrNetwork, ErrNotPrimary, ErrWriteConflict, ErrStaleConfig, ErrInterrupted} {
+		if errors.Is(err, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCommitRetryable reports whether the commit of a transaction failed with an unknown result, such as on a network error
+// during the commit. Only the commit can be retried on it, since running the whole transaction again may apply it twice.
+func IsCommitRetryable(err error) bool {
+	return err != nil && (hasErrorLabel(err, unknownTransactionCommitResultLabel) || errors.Is(err, ErrUnknownCommitResult))
+}
+
+// IsRetryable reports whether the operation may succeed if it is retried as it is.
+// A read is retryable on transient errors, timeouts and the error codes that the drivers retry reads on.
+// A write, which is an error of WithWriteOperation or a BulkError, is retryable only if it was not applied: if the server
+// labels the error as a retryable write, or if its transaction was aborted. See IsOutcomeUnknown for the other writes.
+// Errors of the query itself, such as duplicate key or document validation, are not retryable.
+// An unknown commit result is not retryable as it is.
+func IsRetryable(err error) bool {
+	if err == nil || IsCommitRetryable(err) {
+		return false
+	}
+	if isWrite(err) {
+		return hasErrorLabel(err, retryableWriteErrorLabel) || hasErrorLabel(err, transientTransactionErrorLabel) || IsAuditErr(err)
+	}
+	return isRetryableRead(err)
+}
+
+// IsOutcomeUnknown reports whether a write failed in a way that a read would be retried on, such as a timeout or a
+// network error, but without the label of a retryable write. The write may have been applied, so retrying it as it is
+// may apply it twice. Check whether it was applied, or make it idempotent, before writing it again.
+func IsOutcomeUnknown(err error) bool {
+	return isWrite(err) && !IsRetryable(err) && isRetryableRead(err)
+}
+
+func isRetryableRead(err error) bool {
+	if IsTransient(err) || errors.Is(err, ErrTimeout) || hasErrorLabel(err, retryableWriteErrorLabel) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range retryableCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RetryAfter returns how long to wait before retrying err, or 0 if err is not retryable.
+//
+//	ErrWriteConflict              10ms
+//	ErrStaleConfig, ErrNetwork    100ms
+//	ErrNotPrimary, ErrInterrupted 500ms
+//	ErrTimeout                    1s
+//	other retryable errors        50ms
+func RetryAfter(err error) time.Duration {
+	if !IsRetryable(err) {
+		return 0
+	}
+	for _, r := range retryAfters {
+		if errors.Is(err, r.category) {
+			return r.retryAfter
+		}
+	}
+	return defaultRetryAfter
+}
+
+// labeledError is implemented by the driver errors that carry error labels.
+type labeledError interface {
+	error
+	HasErrorLabel(label string) bool
+}
+
+func hasErrorLabel(err error, label string) bool {
+	var labeledErr labeledError
+	return errors.As(err, &labeledErr) && labeledErr.HasErrorLabel(label)
+}
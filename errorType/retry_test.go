@@ -0,0 +1,96 @@
+package errorType
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_IsRetryable(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		retryable  bool
+		transient  bool
+		retryAfter time.Duration
+	}{
+		{"write conflict", mongo.CommandError{Code: 112}, true, true, 10 * time.Millisecond},
+		{"not primary", mongo.CommandError{Code: 10107}, true, true, 500 * time.Millisecond},
+		{"network", mongo.CommandError{Labels: []string{"NetworkError"}}, true, true, 100 * time.Millisecond},
+		{"transient transaction", mongo.CommandError{Code: 251, Labels: []string{"TransientTransactionError"}}, true, true, defaultRetryAfter},
+		{"unknown commit result", mongo.CommandError{Code: 2, Labels: []string{"UnknownTransactionCommitResult"}}, false, false, 0},
+		{"network error on commit", mongo.CommandError{Labels: []string{"NetworkError", "UnknownTransactionCommitResult"}}, false, false, 0},
+		{"retryable write", mongo.WriteException{Labels: []string{"RetryableWriteError"}, WriteConcernError: &mongo.WriteConcernError{Code: 2}}, true, false, defaultRetryAfter},
+		{"primary stepped down", mongo.CommandError{Code: 189}, true, false, defaultRetryAfter},
+		{"timeout", context.DeadlineExceeded, true, false, time.Second},
+		{"exceeded time limit", mongo.CommandError{Code: 50}, true, false, time.Second},
+		{"duplicate key", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}, false, false, 0},
+		{"document validation", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 121}}}, false, false, 0},
+		{"unauthorized", mongo.CommandError{Code: 13}, false, false, 0},
+		{"not found", mongo.ErrNoDocuments, false, false, 0},
+		{"internal", errors.New("test"), false, false, 0},
+		{"audit", AuditError("col_audit", mongo.CommandError{Code: 13}), true, true, defaultRetryAfter},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := errors.Wrap(ParseAndReturnDBError(c.err, "col", nil, nil, nil), "")
+			assert.Equal(t, c.retryable, IsRetryable(err))
+			assert.Equal(t, c.transient, IsTransient(err))
+			assert.Equal(t, c.retryAfter, RetryAfter(err))
+		})
+	}
+
+	assert.True(t, IsCommitRetryable(mongo.CommandError{Labels: []string{"UnknownTransactionCommitResult"}}))
+	assert.True(t, IsCommitRetryable(TransactionError(ErrUnknownCommitResult, errors.New("commit"))))
+	assert.False(t, IsCommitRetryable(mongo.CommandError{Labels: []string{"TransientTransactionError"}}))
+	assert.False(t, IsCommitRetryable(nil))
+
+	assert.False(t, IsRetryable(nil))
+	assert.False(t, IsRetryable(decodeErr))
+	assert.False(t, IsRetryable(conflictErr))
+	assert.True(t, IsRetryable(MongoClientError(mongo.CommandError{Labels: []string{"NetworkError"}})))
+
+	// the models before a failed model may have been applied, so a bulk write is retryable only with the label
+	writeConflict := mongo.BulkWriteError{WriteError: mongo.WriteError{Index: 0, Code: 112}}
+	duplicateKey := mongo.BulkWriteError{WriteError: mongo.WriteError{Index: 1, Code: 11000}}
+	assert.False(t, IsRetryable(ParseAndReturnBulkError(mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{writeConflict}}, "col", nil, nil)))
+	assert.False(t, IsRetryable(ParseAndReturnBulkError(mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{writeConflict, duplicateKey}}, "col", nil, nil)))
+	assert.True(t, IsRetryable(ParseAndReturnBulkError(mongo.BulkWriteException{Labels: []string{"RetryableWriteError"}, WriteErrors: []mongo.BulkWriteError{writeConflict}}, "col", nil, nil)))
+}
+
+func Test_IsRetryable_write(t *testing.T) {
+	cases := []struct {
+		name           string
+		err            error
+		retryable      bool
+		outcomeUnknown bool
+		retryAfter     time.Duration
+	}{
+		{"network", mongo.CommandError{Labels: []string{"NetworkError"}}, false, true, 0},
+		{"timeout", context.DeadlineExceeded, false, true, 0},
+		{"exceeded time limit", mongo.CommandError{Code: 50}, false, true, 0},
+		{"primary stepped down", mongo.CommandError{Code: 189}, false, true, 0},
+		{"retryable write", mongo.CommandError{Code: 189, Labels: []string{"RetryableWriteError"}}, true, false, defaultRetryAfter},
+		{"retryable network write", mongo.CommandError{Labels: []string{"NetworkError", "RetryableWriteError"}}, true, false, 100 * time.Millisecond},
+		{"transient transaction", mongo.CommandError{Code: 112, Labels: []string{"TransientTransactionError"}}, true, false, 10 * time.Millisecond},
+		{"unknown commit result", mongo.CommandError{Labels: []string{"NetworkError", "UnknownTransactionCommitResult"}}, false, false, 0},
+		{"duplicate key", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}, false, false, 0},
+		{"audit", AuditError("col_audit", mongo.CommandError{Code: 13}), true, false, defaultRetryAfter},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := errors.Wrap(WithWriteOperation(ParseAndReturnDBError(c.err, "col", nil, nil, nil), "UpdateOne"), "")
+			assert.Equal(t, c.retryable, IsRetryable(err))
+			assert.Equal(t, c.outcomeUnknown, IsOutcomeUnknown(err))
+			assert.Equal(t, c.retryAfter, RetryAfter(err))
+		})
+	}
+
+	read := errors.Wrap(WithOperation(ParseAndReturnDBError(context.DeadlineExceeded, "col", nil, nil, nil), "FindOne"), "")
+	assert.True(t, IsRetryable(read))
+	assert.False(t, IsOutcomeUnknown(read))
+}
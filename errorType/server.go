@@ -0,0 +1,116 @@
+package errorType
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Categories of server errors that ParseAndReturnDBError tells apart from internal errors.
+var (
+	ErrWriteConflict     = errors.New("write conflict")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrNotPrimary        = errors.New("not primary")
+	ErrNetwork           = errors.New("network")
+	ErrCursorNotFound    = errors.New("cursor not found")
+	ErrExceededTimeLimit = errors.New("exceeded time limit")
+	ErrStaleConfig       = errors.New("stale config")
+	ErrInterrupted       = errors.New("interrupted")
+)
+
+// serverErrorCategories maps server error codes to categories. The first category with a code of the error is used.
+//
+//	code                 name                                                                 category
+//	112                  WriteConflict                                                        ErrWriteConflict
+//	13, 18               Unauthorized, AuthenticationFailed                                   ErrUnauthorized
+//	10107, 13435, 13436  NotWritablePrimary, NotPrimaryNoSecondaryOk, NotPrimaryOrSecondary   ErrNotPrimary
+//	43                   CursorNotFound                                                       ErrCursorNotFound
+//	50                   MaxTimeMSExpired                                                     ErrExceededTimeLimit
+//	13388, 63, 150, 249  StaleConfig, StaleShardVersion, StaleEpoch, StaleDbVersion           ErrStaleConfig
+//	11600, 11601, 11602  InterruptedAtShutdown, Interrupted, InterruptedDueToReplStateChange  ErrInterrupted
+//
+// Network errors other than timeouts are ErrNetwork.
+var serverErrorCategories = []struct {
+	codes    []int
+	category error
+}{
+	{[]int{112}, ErrWriteConflict},
+	{[]int{13, 18}, ErrUnauthorized},
+	{[]int{10107, 13435, 13436}, ErrNotPrimary},
+	{[]int{43}, ErrCursorNotFound},
+	{[]int{50}, ErrExceededTimeLimit},
+	{[]int{13388, 63, 150, 249}, ErrStaleConfig},
+	{[]int{11600, 11601, 11602}, ErrInterrupted},
+}
+
+type serverError struct {
+	basicQueryInfo
+}
+
+// ServerError returns an error of one of the server error categories, such as ErrWriteConflict.
+func ServerError(col string, filter, update, doc interface{}, category error, mongoErr error) error {
+	err := &serverError{}
+	err.setBasicError(category, col, filter, update, doc)
+	err.driverErr = mongoErr
+	return err
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("%s %s, err: %s ", e.collection, e.category.Error(), e.driverErr.Error()) + getBasicInfoErrorMsg(e.basicQueryInfo)
+}
+
+// Is reports whether target is the category of the error. ErrExceededTimeLimit is a timeout as well.
+func (e *serverError) Is(target error) bool {
+	return target == e.category || (e.category == ErrExceededTimeLimit && target == ErrTimeout)
+}
+
+// serverErrorCategory returns the category of err, or nil if err is not one of the server error categories.
+func serverErrorCategory(err error) error {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, c := range serverErrorCategories {
+			for _, code := range c.codes {
+				if serverErr.HasErrorCode(code) {
+					return c.category
+				}
+			}
+		}
+	}
+	if mongo.IsNetworkError(err) && !mongo.IsTimeout(err) {
+		return ErrNetwork
+	}
+	return nil
+}
+
+func IsWriteConflictErr(err error) bool {
+	return errors.Is(err, ErrWriteConflict)
+}
+
+func IsUnauthorizedErr(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func IsNotPrimaryErr(err error) bool {
+	return errors.Is(err, ErrNotPrimary)
+}
+
+func IsNetworkErr(err error) bool {
+	return errors.Is(err, ErrNetwork)
+}
+
+func IsCursorNotFoundErr(err error) bool {
+	return errors.Is(err, ErrCursorNotFound)
+}
+
+func IsExceededTimeLimitErr(err error) bool {
+	return errors.Is(err, ErrExceededTimeLimit)
+}
+
+func IsStaleConfigErr(err error) bool {
+	return errors.Is(err, ErrStaleConfig)
+}
+
+func IsInterruptedErr(err error) bool {
+	return errors.Is(err, ErrInterrupted)
+}
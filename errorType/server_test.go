@@ -0,0 +1,66 @@
+package errorType
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_ParseAndReturnDBError_ServerErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		category error
+		is       func(error) bool
+	}{
+		{"write conflict", mongo.CommandError{Code: 112}, ErrWriteConflict, IsWriteConflictErr},
+		{"write conflict in write exception", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 112}}}, ErrWriteConflict, IsWriteConflictErr},
+		{"unauthorized", mongo.CommandError{Code: 13}, ErrUnauthorized, IsUnauthorizedErr},
+		{"authentication failed", mongo.CommandError{Code: 18}, ErrUnauthorized, IsUnauthorizedErr},
+		{"not writable primary", mongo.CommandError{Code: 10107}, ErrNotPrimary, IsNotPrimaryErr},
+		{"not primary no secondary ok", mongo.CommandError{Code: 13435}, ErrNotPrimary, IsNotPrimaryErr},
+		{"not primary in write concern", mongo.WriteException{WriteConcernError: &mongo.WriteConcernError{Code: 13436}}, ErrNotPrimary, IsNotPrimaryErr},
+		{"network", mongo.CommandError{Labels: []string{"NetworkError"}}, ErrNetwork, IsNetworkErr},
+		{"cursor not found", mongo.CommandError{Code: 43}, ErrCursorNotFound, IsCursorNotFoundErr},
+		{"exceeded time limit", mongo.CommandError{Code: 50}, ErrExceededTimeLimit, IsExceededTimeLimitErr},
+		{"stale config", mongo.CommandError{Code: 13388}, ErrStaleConfig, IsStaleConfigErr},
+		{"stale shard version", mongo.CommandError{Code: 63}, ErrStaleConfig, IsStaleConfigErr},
+		{"stale epoch", mongo.CommandError{Code: 150}, ErrStaleConfig, IsStaleConfigErr},
+		{"stale db version", mongo.CommandError{Code: 249}, ErrStaleConfig, IsStaleConfigErr},
+		{"interrupted at shutdown", mongo.CommandError{Code: 11600}, ErrInterrupted, IsInterruptedErr},
+		{"interrupted", mongo.CommandError{Code: 11601}, ErrInterrupted, IsInterruptedErr},
+		{"interrupted due to repl state change", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11602}}}, ErrInterrupted, IsInterruptedErr},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ParseAndReturnDBError(c.err, "col", nil, nil, nil)
+			assert.True(t, errors.Is(err, c.category))
+			assert.True(t, c.is(errors.Wrap(err, "")))
+			assert.True(t, IsDBInternalErr(err))
+			assert.False(t, errors.Is(err, ErrInternal))
+			assert.Contains(t, err.Error(), c.category.Error())
+			assert.Equal(t, c.err, errors.Unwrap(err))
+		})
+	}
+}
+
+func Test_ServerErrors_Timeout(t *testing.T) {
+	err := ParseAndReturnDBError(mongo.CommandError{Code: 50}, "col", nil, nil, nil)
+	assert.True(t, IsTimeoutError(err))
+
+	err = ParseAndReturnDBError(mongo.CommandError{Labels: []string{"NetworkError", "NetworkTimeoutError"}}, "col", nil, nil, nil)
+	assert.True(t, IsTimeoutError(err))
+	assert.False(t, IsNetworkErr(err))
+
+	err = ParseAndReturnDBError(context.DeadlineExceeded, "col", nil, nil, nil)
+	assert.False(t, IsExceededTimeLimitErr(err))
+}
+
+func Test_ServerErrors_Internal(t *testing.T) {
+	err := ParseAndReturnDBError(mongo.CommandError{Code: 2}, "col", nil, nil, nil)
+	assert.True(t, errors.Is(err, ErrInternal))
+	assert.False(t, IsWriteConflictErr(err))
+}
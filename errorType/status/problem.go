@@ -0,0 +1,65 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is a problem details(RFC 7807) response. It never contains the query info or the driver error of the error.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Problem returns the problem of err for the request URI instance, which may be empty.
+func (m *Mapper) Problem(err error, instance string) *Problem {
+	rule := m.Rule(err)
+	problem := &Problem{
+		Type:     rule.Type,
+		Title:    statusText(rule.HTTPStatus),
+		Status:   rule.HTTPStatus,
+		Detail:   rule.Detail,
+		Instance: instance,
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Detail == "" && rule.Err != nil && rule.HTTPStatus >= 400 && rule.HTTPStatus < 500 {
+		problem.Detail = rule.Err.Error()
+	}
+	return problem
+}
+
+// WriteProblem writes the problem of err as the response, with the path of r as the instance.
+func (m *Mapper) WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	instance := ""
+	if r != nil && r.URL != nil {
+		instance = r.URL.Path
+	}
+	problem := m.Problem(err, instance)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// NewProblem returns the problem of err with the default rules.
+func NewProblem(err error, instance string) *Problem {
+	return defaultMapper.Problem(err, instance)
+}
+
+// WriteProblem writes the problem of err with the default rules.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	defaultMapper.WriteProblem(w, r, err)
+}
+
+func statusText(code int) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(code)
+}
@@ -0,0 +1,104 @@
+// Package status maps the errors of the wrapper to HTTP status codes and gRPC codes,
+// and builds problem+json(RFC 7807) responses from them.
+package status
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+)
+
+// Rule maps the errors that match Err with errors.Is to a status.
+type Rule struct {
+	Err        error
+	HTTPStatus int
+	GRPCCode   Code
+
+	// The type URI of the problem. "about:blank" if empty.
+	Type string
+	// The detail of the problem. If empty, the message of Err is used for 4xx statuses and nothing for the others.
+	Detail string
+}
+
+// defaultRules are checked in order, so that the errors of a BulkError that match an earlier rule win over ErrBulk.
+var defaultRules = []Rule{
+	{Err: errorType.ErrAudit, HTTPStatus: http.StatusConflict, GRPCCode: Aborted},
+	{Err: errorType.ErrNotFound, HTTPStatus: http.StatusNotFound, GRPCCode: NotFound},
+	{Err: errorType.NotMatchedAnyErr, HTTPStatus: http.StatusNotFound, GRPCCode: NotFound},
+	{Err: errorType.ErrDuplicateKey, HTTPStatus: http.StatusConflict, GRPCCode: AlreadyExists},
+	{Err: errorType.ErrVersionConflict, HTTPStatus: http.StatusConflict, GRPCCode: Aborted},
+	{Err: errorType.ErrWriteConflict, HTTPStatus: http.StatusConflict, GRPCCode: Aborted},
+	{Err: errorType.ErrTransientTransaction, HTTPStatus: http.StatusConflict, GRPCCode: Aborted},
+	{Err: errorType.ErrDocumentValidation, HTTPStatus: http.StatusUnprocessableEntity, GRPCCode: InvalidArgument},
+	{Err: errorType.UpsertKeyMissingErr, HTTPStatus: http.StatusUnprocessableEntity, GRPCCode: InvalidArgument},
+	{Err: errorType.ErrTimeout, HTTPStatus: http.StatusGatewayTimeout, GRPCCode: DeadlineExceeded},
+	{Err: context.DeadlineExceeded, HTTPStatus: http.StatusGatewayTimeout, GRPCCode: DeadlineExceeded},
+	{Err: context.Canceled, HTTPStatus: StatusClientClosedRequest, GRPCCode: Canceled},
+	{Err: errorType.ErrNotPrimary, HTTPStatus: http.StatusServiceUnavailable, GRPCCode: Unavailable},
+	{Err: errorType.ErrNetwork, HTTPStatus: http.StatusServiceUnavailable, GRPCCode: Unavailable},
+	{Err: errorType.ErrStaleConfig, HTTPStatus: http.StatusServiceUnavailable, GRPCCode: Unavailable},
+	{Err: errorType.ErrInterrupted, HTTPStatus: http.StatusServiceUnavailable, GRPCCode: Unavailable},
+}
+
+// StatusClientClosedRequest is the non-standard status of a request that the client canceled.
+const StatusClientClosedRequest = 499
+
+// DefaultRules returns a copy of the rules of the default Mapper.
+//
+//	HTTP  gRPC              errors
+//	404   NotFound          ErrNotFound, NotMatchedAnyErr
+//	409   AlreadyExists     ErrDuplicateKey
+//	409   Aborted           ErrAudit, ErrVersionConflict, ErrWriteConflict, ErrTransientTransaction
+//	422   InvalidArgument   ErrDocumentValidation, UpsertKeyMissingErr
+//	504   DeadlineExceeded  ErrTimeout, context.DeadlineExceeded
+//	499   Canceled          context.Canceled
+//	503   Unavailable       ErrNotPrimary, ErrNetwork, ErrStaleConfig, ErrInterrupted
+//	500   Internal          the others
+func DefaultRules() []Rule {
+	return append([]Rule(nil), defaultRules...)
+}
+
+// Mapper maps errors to statuses with its rules. The first rule that matches an error is used.
+type Mapper struct {
+	rules []Rule
+}
+
+// NewMapper returns a Mapper that checks rules before the default rules.
+func NewMapper(rules ...Rule) *Mapper {
+	return &Mapper{rules: append(append([]Rule(nil), rules...), defaultRules...)}
+}
+
+var defaultMapper = NewMapper()
+
+// Rule returns the rule that matches err. nil err is 200(OK), and an error that matches no rule is 500(Internal).
+func (m *Mapper) Rule(err error) Rule {
+	if err == nil {
+		return Rule{HTTPStatus: http.StatusOK, GRPCCode: OK}
+	}
+	for _, rule := range m.rules {
+		if errors.Is(err, rule.Err) {
+			return rule
+		}
+	}
+	return Rule{HTTPStatus: http.StatusInternalServerError, GRPCCode: Internal}
+}
+
+func (m *Mapper) HTTPStatus(err error) int {
+	return m.Rule(err).HTTPStatus
+}
+
+func (m *Mapper) GRPCCode(err error) Code {
+	return m.Rule(err).GRPCCode
+}
+
+// HTTPStatus returns the HTTP status code of err with the default rules.
+func HTTPStatus(err error) int {
+	return defaultMapper.HTTPStatus(err)
+}
+
+// GRPCCode returns the gRPC code of err with the default rules.
+func GRPCCode(err error) Code {
+	return defaultMapper.GRPCCode(err)
+}
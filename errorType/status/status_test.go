@@ -0,0 +1,94 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_HTTPStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		status   int
+		grpcCode Code
+	}{
+		{"nil", nil, http.StatusOK, OK},
+		{"not found", errorType.NotFoundError("col", nil, nil, nil), http.StatusNotFound, NotFound},
+		{"not matched", errors.Wrap(errorType.NotMatchedAnyErr, "update"), http.StatusNotFound, NotFound},
+		{"duplicate key", errorType.DuplicatedKeyError("col", nil, nil, nil, errors.New("E11000")), http.StatusConflict, AlreadyExists},
+		{"version conflict", errorType.VersionConflictError("col", nil, nil, nil), http.StatusConflict, Aborted},
+		{"write conflict", errorType.ParseAndReturnDBError(mongo.CommandError{Code: 112}, "col", nil, nil, nil), http.StatusConflict, Aborted},
+		{"validation", errorType.DocumentValidationError("col", nil, nil, nil, errors.New("121")), http.StatusUnprocessableEntity, InvalidArgument},
+		{"timeout", errorType.TimeoutError("col", nil, nil, nil, context.DeadlineExceeded), http.StatusGatewayTimeout, DeadlineExceeded},
+		{"exceeded time limit", errorType.ParseAndReturnDBError(mongo.CommandError{Code: 50}, "col", nil, nil, nil), http.StatusGatewayTimeout, DeadlineExceeded},
+		{"canceled", errors.Wrap(context.Canceled, "find"), StatusClientClosedRequest, Canceled},
+		{"not primary", errorType.ParseAndReturnDBError(mongo.CommandError{Code: 10107}, "col", nil, nil, nil), http.StatusServiceUnavailable, Unavailable},
+		{"bulk", &errorType.BulkError{Errors: []errorType.BulkWriteError{{Index: 0, Err: errorType.DuplicatedKeyError("col", nil, nil, nil, errors.New("E11000"))}}}, http.StatusConflict, AlreadyExists},
+		{"bulk of different errors", &errorType.BulkError{Errors: []errorType.BulkWriteError{{Index: 0, Err: errorType.DuplicatedKeyError("col", nil, nil, nil, errors.New("E11000"))}, {Index: 1, Err: errorType.DocumentValidationError("col", nil, nil, nil, errors.New("failed validation"))}}}, http.StatusInternalServerError, Internal},
+		{"internal", errorType.InternalError("col", nil, nil, nil, errors.New("internal")), http.StatusInternalServerError, Internal},
+		{"unknown", errors.New("unknown"), http.StatusInternalServerError, Internal},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.status, HTTPStatus(c.err))
+			assert.Equal(t, c.grpcCode, GRPCCode(c.err))
+		})
+	}
+}
+
+func Test_NewMapper(t *testing.T) {
+	errBusinessRule := errors.New("business rule")
+	mapper := NewMapper(
+		Rule{Err: errorType.ErrNotFound, HTTPStatus: http.StatusGone, GRPCCode: NotFound},
+		Rule{Err: errBusinessRule, HTTPStatus: http.StatusBadRequest, GRPCCode: FailedPrecondition},
+	)
+	assert.Equal(t, http.StatusGone, mapper.HTTPStatus(errorType.NotFoundError("col", nil, nil, nil)))
+	assert.Equal(t, FailedPrecondition, mapper.GRPCCode(errors.Wrap(errBusinessRule, "")))
+	assert.Equal(t, http.StatusConflict, mapper.HTTPStatus(errorType.VersionConflictError("col", nil, nil, nil)))
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(errorType.NotFoundError("col", nil, nil, nil)))
+	assert.Equal(t, len(defaultRules), len(DefaultRules()))
+}
+
+func Test_Problem(t *testing.T) {
+	err := errorType.NotFoundError("accounts", bson.M{"email": "kim@example.com"}, nil, nil)
+	assert.Equal(t, &Problem{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "not found", Instance: "/accounts/1"}, NewProblem(err, "/accounts/1"))
+
+	internal := errorType.InternalError("accounts", bson.M{"email": "kim@example.com"}, nil, nil, errors.New("internal"))
+	assert.Equal(t, &Problem{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError}, NewProblem(internal, ""))
+	assert.Equal(t, "Client Closed Request", NewProblem(context.Canceled, "").Title)
+
+	mapper := NewMapper(Rule{Err: errorType.ErrDuplicateKey, HTTPStatus: http.StatusConflict, Type: "https://example.com/problems/duplicate", Detail: "the account already exists"})
+	assert.Equal(t, &Problem{Type: "https://example.com/problems/duplicate", Title: "Conflict", Status: http.StatusConflict, Detail: "the account already exists"},
+		mapper.Problem(errorType.DuplicatedKeyError("accounts", nil, nil, nil, errors.New("E11000")), ""))
+}
+
+func Test_WriteProblem(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	WriteProblem(recorder, request, errorType.NotFoundError("accounts", bson.M{"email": "kim@example.com"}, nil, nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, ProblemContentType, recorder.Header().Get("Content-Type"))
+	assert.NotContains(t, recorder.Body.String(), "kim@example.com")
+
+	var problem Problem
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, Problem{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "not found", Instance: "/accounts/1"}, problem)
+}
+
+func Test_Code(t *testing.T) {
+	// the numbers of google.golang.org/grpc/codes
+	assert.Equal(t, Code(5), NotFound)
+	assert.Equal(t, Code(16), Unauthenticated)
+	assert.Equal(t, "DeadlineExceeded", DeadlineExceeded.String())
+	assert.Equal(t, "Code(17)", Code(17).String())
+}
@@ -0,0 +1,75 @@
+package errorType
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Categories of the errors of a transaction that the server labels.
+var (
+	// The transaction was aborted by a temporary state of the cluster. The whole transaction can be retried.
+	ErrTransientTransaction = errors.New("transient transaction")
+	// The commit of the transaction failed and it may or may not have been applied.
+	ErrUnknownCommitResult = errors.New("unknown transaction commit result")
+)
+
+type transactionError struct {
+	category  error
+	driverErr error
+}
+
+// TransactionError returns an error of ErrTransientTransaction or ErrUnknownCommitResult.
+func TransactionError(category error, mongoErr error) error {
+	return &transactionError{category: category, driverErr: mongoErr}
+}
+
+func (e *transactionError) Error() string {
+	return fmt.Sprintf("%s, err: %s", e.category.Error(), e.driverErr.Error())
+}
+
+func (e *transactionError) Is(target error) bool {
+	return target == e.category
+}
+
+func (e *transactionError) Unwrap() error {
+	return e.driverErr
+}
+
+// ParseAndReturnTransactionError classifies the error of a transaction.
+// Errors labeled UnknownTransactionCommitResult or TransientTransactionError are returned as a transaction error
+// that wraps the error, other errors of this package and errors that are not of the driver are returned as they are,
+// and the other driver errors are classified like ParseAndReturnDBError.
+func ParseAndReturnTransactionError(err error) error {
+	var txErr *transactionError
+	if err == nil || errors.As(err, &txErr) {
+		return err
+	}
+	if hasErrorLabel(err, unknownTransactionCommitResultLabel) {
+		return TransactionError(ErrUnknownCommitResult, err)
+	}
+	if hasErrorLabel(err, transientTransactionErrorLabel) {
+		return TransactionError(ErrTransientTransaction, err)
+	}
+	var f fielder
+	if errors.As(err, &f) || !isDriverError(err) {
+		return err
+	}
+	return ParseAndReturnDBError(err, "", nil, nil, nil)
+}
+
+func isDriverError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) || mongo.IsNetworkError(err) || mongo.IsTimeout(err) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, mongo.ErrClientDisconnected)
+}
+
+func IsTransientTransactionErr(err error) bool {
+	return errors.Is(err, ErrTransientTransaction)
+}
+
+func IsUnknownCommitResultErr(err error) bool {
+	return errors.Is(err, ErrUnknownCommitResult)
+}
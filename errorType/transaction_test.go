@@ -0,0 +1,35 @@
+package errorType
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Test_ParseAndReturnTransactionError(t *testing.T) {
+	assert.Nil(t, ParseAndReturnTransactionError(nil))
+
+	commitErr := mongo.CommandError{Code: 91, Labels: []string{"UnknownTransactionCommitResult"}}
+	err := ParseAndReturnTransactionError(commitErr)
+	assert.True(t, IsUnknownCommitResultErr(err))
+	assert.False(t, IsTransientTransactionErr(err))
+	assert.Equal(t, commitErr, errors.Unwrap(err))
+	assert.Equal(t, err, ParseAndReturnTransactionError(err))
+
+	conflictErr := ParseAndReturnDBError(mongo.CommandError{Code: 112, Labels: []string{"TransientTransactionError"}}, "col", nil, nil, nil)
+	err = ParseAndReturnTransactionError(conflictErr)
+	assert.True(t, IsTransientTransactionErr(err))
+	assert.True(t, IsWriteConflictErr(err))
+	assert.True(t, IsTransient(err))
+	assert.Equal(t, "transient transaction", Fields(err)[FieldCategory])
+	assert.Equal(t, "col", Fields(err)[FieldCollection])
+
+	assert.Equal(t, notFoundErr, ParseAndReturnTransactionError(notFoundErr))
+	businessErr := errors.New("insufficient limit")
+	assert.Equal(t, businessErr, ParseAndReturnTransactionError(businessErr))
+	assert.True(t, IsTimeoutError(ParseAndReturnTransactionError(context.DeadlineExceeded)))
+	assert.True(t, IsNotPrimaryErr(ParseAndReturnTransactionError(mongo.CommandError{Code: 10107})))
+}
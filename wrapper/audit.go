@@ -0,0 +1,416 @@
+package wrapper
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AuditInsert  = "insert"
+	AuditUpdate  = "update"
+	AuditReplace = "replace"
+	AuditDelete  = "delete"
+
+	defaultAuditCollectionSuffix = "_audit"
+)
+
+// AuditEntry is a record of a write on an audited collection.
+// Filter and Update are kept as extended JSON, Before and After hold the document images when the operation returns them.
+type AuditEntry struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Collection string             `json:"collection" bson:"collection"`
+	Operation  string             `json:"operation" bson:"operation"`
+	DocumentId interface{}        `json:"document_id,omitempty" bson:"document_id,omitempty"`
+	Filter     string             `json:"filter,omitempty" bson:"filter,omitempty"`
+	Update     string             `json:"update,omitempty" bson:"update,omitempty"`
+	Before     bson.Raw           `json:"before,omitempty" bson:"before,omitempty"`
+	After      bson.Raw           `json:"after,omitempty" bson:"after,omitempty"`
+	Actor      string             `json:"actor,omitempty" bson:"actor,omitempty"`
+	RequestId  string             `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	Timestamp  time.Time          `json:"timestamp" bson:"timestamp"`
+}
+
+type actorKey struct{}
+
+type requestIdKey struct{}
+
+// WithActor returns a context that records the actor on audit entries.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithRequestId returns a context that records the request id on audit entries.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+// AuditHistory returns the audit entries of a document in the order they were written.
+func (col *Collection[T]) AuditHistory(logger Logger, documentId interface{}, opts ...*options.FindOptions) ([]AuditEntry, error) {
+	if !col.auditEnabled() {
+		return nil, errorType.AuditDisabledErr
+	}
+	ctx, ctxCancel := col.newContext(logger)
+	defer ctxCancel()
+
+	filter := bson.D{{Key: "collection", Value: col.Name()}, {Key: "document_id", Value: documentId}}
+	findOpts := append([]*options.FindOptions{options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})}, opts...)
+	cursor, err := col.auditCollection.Find(ctx, filter, findOpts...)
+	if err != nil {
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(err, col.auditCollection.Name(), filter, nil, nil), "AuditHistory")
+	}
+	entries, err := DecodeCursor[AuditEntry](cursor)
+	if err != nil {
+		return nil, col.withOperation(errorType.DecodeError(col.auditCollection.Name(), filter, nil, nil, err), "AuditHistory")
+	}
+	return entries, nil
+}
+
+func (col *Collection[T]) auditEnabled() bool {
+	return col.auditCollection != nil
+}
+
+// audit writes the entries with the context of the operation, so writes inside a transaction are audited in the same transaction.
+// If the entries cannot be written in a transaction, it returns an audit error, so that the transaction aborts with the audited write.
+// Outside a transaction, the write has been applied and cannot be undone, so the error is reported to onAuditError instead.
+func (col *Collection[T]) audit(ctx context.Context, entries ...AuditEntry) error {
+	if !col.auditEnabled() || len(entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	documents := make([]interface{}, len(entries))
+	for i := range entries {
+		entries[i].Collection = col.Name()
+		entries[i].Actor = ActorFromContext(ctx)
+		entries[i].RequestId = RequestIdFromContext(ctx)
+		entries[i].Timestamp = now
+		documents[i] = entries[i]
+	}
+	if _, err := col.auditCollection.InsertMany(ctx, documents); err != nil {
+		err = errorType.AuditError(col.auditCollection.Name(), err)
+		if inTransaction(ctx) {
+			return err
+		}
+		col.onAuditError(ctx, entries, err)
+	}
+	return nil
+}
+
+func logAuditError(_ context.Context, entries []AuditEntry, err error) {
+	log.Printf("%d audit entries of %s were not written: %s", len(entries), entries[0].Collection, err)
+}
+
+func (col *Collection[T]) auditEntry(operation string, filter, update interface{}, before, after bson.Raw, documentId interface{}) AuditEntry {
+	return AuditEntry{
+		Operation:  operation,
+		DocumentId: documentId,
+		Filter:     extJSON(filter),
+		Update:     extJSON(update),
+		Before:     before,
+		After:      after,
+	}
+}
+
+func (col *Collection[T]) auditInsertMany(ctx context.Context, documents []interface{}, insertedIds []interface{}) error {
+	if !col.auditEnabled() {
+		return nil
+	}
+	entries := make([]AuditEntry, 0, len(documents))
+	for i, document := range documents {
+		if i >= len(insertedIds) {
+			break
+		}
+		entries = append(entries, col.auditEntry(AuditInsert, nil, nil, nil, toRaw(document), insertedIds[i]))
+	}
+	return col.audit(ctx, entries...)
+}
+
+// auditUpdate audits an update or a replacement of the documents with the ids, or of the document it upserted.
+// Without an id, the write is audited with an entry without document id.
+func (col *Collection[T]) auditUpdate(ctx context.Context, operation string, filter, update, replacement interface{}, result *mongo.UpdateResult, ids []interface{}) error {
+	if !col.auditEnabled() || (result.MatchedCount == 0 && result.UpsertedCount == 0) {
+		return nil
+	}
+	if result.UpsertedID != nil {
+		// an upsert matched no document
+		ids = []interface{}{result.UpsertedID}
+	}
+	if len(ids) == 0 {
+		ids = []interface{}{nil}
+	}
+	var after bson.Raw
+	if replacement != nil {
+		after = toRaw(replacement)
+	}
+	entries := make([]AuditEntry, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, col.auditEntry(operation, filter, update, nil, after, id))
+	}
+	return col.audit(ctx, entries...)
+}
+
+// auditDelete audits a delete of the documents with the ids, or with an entry without document id if there is none.
+func (col *Collection[T]) auditDelete(ctx context.Context, filter interface{}, result *mongo.DeleteResult, ids []interface{}) error {
+	if !col.auditEnabled() || result.DeletedCount == 0 {
+		return nil
+	}
+	if len(ids) == 0 {
+		ids = []interface{}{nil}
+	}
+	entries := make([]AuditEntry, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, col.auditEntry(AuditDelete, filter, nil, nil, nil, id))
+	}
+	return col.audit(ctx, entries...)
+}
+
+// matchedIds returns the ids of the documents that a write of many documents matches, so that it is audited by document.
+// It is a query before the write, so outside a transaction the ids are of the documents matched just before the write.
+// It returns nil if audit is disabled.
+func (col *Collection[T]) matchedIds(logger Logger, ctx context.Context, filter interface{}, collation *options.Collation, hint interface{}) ([]interface{}, error) {
+	if !col.auditEnabled() {
+		return nil, nil
+	}
+	findOpts := options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}})
+	findOpts.Collation = collation
+	findOpts.Hint = hint
+	ctx, startTime := col.startQuery(ctx)
+	cursor, err := col.Collection.Find(ctx, filter, findOpts)
+	var documents []bson.Raw
+	if err == nil {
+		err = cursor.All(ctx, &documents)
+	}
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "matchedIds", Filter: filter, hint: hint}, err)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]interface{}, 0, len(documents))
+	for _, document := range documents {
+		ids = append(ids, document.Lookup("_id"))
+	}
+	return ids, nil
+}
+
+// upsertIdOf returns the id of the document that an update of one document upserts, and the update that inserts it with
+// the id. If the filter has no _id, the id is generated and set with $setOnInsert. It returns false if the id cannot be
+// known before the write, such as for an update pipeline.
+func (col *Collection[T]) upsertIdOf(filter, update interface{}, upsert bool) (interface{}, interface{}, bool) {
+	if !upsert {
+		return update, nil, true
+	}
+	if id := col.documentIdOf(filter); id != nil {
+		return update, id, true
+	}
+	doc, pipeline, ok := toUpdate(update)
+	if !ok || pipeline != nil || updatedFields(doc, nil)["_id"] {
+		return update, nil, false
+	}
+	if filterDoc, err := toDocument(col.registry, filter); err != nil || mentionsId(filterDoc) {
+		return update, nil, false
+	}
+	id := primitive.NewObjectID()
+	return addOperand(doc, "$setOnInsert", bson.E{Key: "_id", Value: id}), id, true
+}
+
+// replacementUpsertIdOf returns the id of the document that a replacement upserts, which is the _id of its filter or of
+// the replacement. It returns false if the id cannot be known before the write.
+func (col *Collection[T]) replacementUpsertIdOf(filter, replacement interface{}, upsert bool) (interface{}, bool) {
+	if !upsert {
+		return nil, true
+	}
+	if id := col.documentIdOf(filter); id != nil {
+		return id, true
+	}
+	if id := col.documentIdOf(replacement); id != nil {
+		return id, true
+	}
+	return nil, false
+}
+
+// nonNil returns the id as a slice of ids, or nil if it is nil.
+func nonNil(id interface{}) []interface{} {
+	if id == nil {
+		return nil
+	}
+	return []interface{}{id}
+}
+
+// mentionsId tells whether a filter has a condition on _id at any level, which the server may take the id of an upsert from.
+func mentionsId(v interface{}) bool {
+	switch value := v.(type) {
+	case bson.D:
+		for _, element := range value {
+			if element.Key == "_id" || mentionsId(element.Value) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, item := range value {
+			if mentionsId(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findAndModifyResult returns the result of a write of one document written with findAndModify, and the image of the
+// document before the write. Without a document before, the write upserted upsertedId, or matched nothing if it is nil.
+// A matched document counts as modified, since findAndModify does not tell whether the write changed it.
+func findAndModifyResult(singleResult *mongo.SingleResult, upsertedId interface{}) (*mongo.UpdateResult, bson.Raw, error) {
+	before, err := singleResult.DecodeBytes()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if upsertedId == nil {
+			return &mongo.UpdateResult{}, nil, nil
+		}
+		return &mongo.UpdateResult{UpsertedCount: 1, UpsertedID: upsertedId}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, before, nil
+}
+
+// auditOne audits a write of one document written with findAndModify, with the id and the image before the write.
+func (col *Collection[T]) auditOne(ctx context.Context, operation string, filter, update, replacement interface{}, result *mongo.UpdateResult, before bson.Raw) error {
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return nil
+	}
+	documentId := result.UpsertedID
+	if before != nil {
+		documentId = before.Lookup("_id")
+	}
+	var after bson.Raw
+	if replacement != nil {
+		after = toRaw(replacement)
+	}
+	return col.audit(ctx, col.auditEntry(operation, filter, update, before, after, documentId))
+}
+
+// auditSingleResult audits a find-and-modify operation with the document it returned.
+// The result is returned as it is, with the audit error of a transaction if the entry cannot be written.
+func (col *Collection[T]) auditSingleResult(ctx context.Context, singleResult *mongo.SingleResult, operation string, filter, update, replacement interface{}, returnsAfter bool) (*mongo.SingleResult, error) {
+	if !col.auditEnabled() {
+		return singleResult, nil
+	}
+	raw, err := singleResult.DecodeBytes()
+	if err != nil {
+		return singleResult, nil
+	}
+	var documentId interface{}
+	if id, err := raw.LookupErr("_id"); err == nil {
+		documentId = id
+	}
+	entry := col.auditEntry(operation, filter, update, nil, nil, documentId)
+	if returnsAfter {
+		entry.After = raw
+	} else {
+		entry.Before = raw
+		if replacement != nil {
+			entry.After = toRaw(replacement)
+		}
+	}
+	return singleResult, col.audit(ctx, entry)
+}
+
+// auditModels audits the models of a bulk write. The models are not resolved to the documents they write, so the entries
+// of UpdateOne, ReplaceOne and DeleteOne models have the document id only if their filter matches a single _id, and the
+// entries of UpdateMany and DeleteMany models have none.
+func (col *Collection[T]) auditModels(ctx context.Context, models []mongo.WriteModel) error {
+	if !col.auditEnabled() {
+		return nil
+	}
+	entries := make([]AuditEntry, 0, len(models))
+	for _, model := range models {
+		switch m := model.(type) {
+		case *mongo.InsertOneModel:
+			entries = append(entries, col.auditEntry(AuditInsert, nil, nil, nil, toRaw(m.Document), col.documentIdOf(m.Document)))
+		case *mongo.UpdateOneModel:
+			entries = append(entries, col.auditEntry(AuditUpdate, m.Filter, m.Update, nil, nil, col.documentIdOf(m.Filter)))
+		case *mongo.UpdateManyModel:
+			entries = append(entries, col.auditEntry(AuditUpdate, m.Filter, m.Update, nil, nil, nil))
+		case *mongo.ReplaceOneModel:
+			entries = append(entries, col.auditEntry(AuditReplace, m.Filter, nil, nil, toRaw(m.Replacement), col.documentIdOf(m.Filter)))
+		case *mongo.DeleteOneModel:
+			entries = append(entries, col.auditEntry(AuditDelete, m.Filter, nil, nil, nil, col.documentIdOf(m.Filter)))
+		case *mongo.DeleteManyModel:
+			entries = append(entries, col.auditEntry(AuditDelete, m.Filter, nil, nil, nil, nil))
+		}
+	}
+	return col.audit(ctx, entries...)
+}
+
+func returnsDocumentAfter(returnDocument *options.ReturnDocument) bool {
+	return returnDocument != nil && *returnDocument == options.After
+}
+
+// documentIdOf returns the _id of a document, or of a filter that matches a single _id.
+func (col *Collection[T]) documentIdOf(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	doc, err := toDocument(col.registry, v)
+	if err != nil {
+		return nil
+	}
+	id, ok := lookup(doc, "_id")
+	if !ok {
+		return nil
+	}
+	if operators, isDocument := id.(bson.D); isDocument && len(operators) > 0 && len(operators[0].Key) > 0 && operators[0].Key[0] == '$' {
+		return nil
+	}
+	return id
+}
+
+func updateToFindOneAndUpdateOptions(opts ...*options.UpdateOptions) *options.FindOneAndUpdateOptions {
+	updateOpts := options.MergeUpdateOptions(opts...)
+	findOpts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	findOpts.ArrayFilters = updateOpts.ArrayFilters
+	findOpts.BypassDocumentValidation = updateOpts.BypassDocumentValidation
+	findOpts.Collation = updateOpts.Collation
+	findOpts.Comment = updateOpts.Comment
+	findOpts.Hint = updateOpts.Hint
+	findOpts.Upsert = updateOpts.Upsert
+	findOpts.Let = updateOpts.Let
+	return findOpts
+}
+
+func replaceToFindOneAndReplaceOptions(opts ...*options.ReplaceOptions) *options.FindOneAndReplaceOptions {
+	replaceOpts := options.MergeReplaceOptions(opts...)
+	findOpts := options.FindOneAndReplace().SetReturnDocument(options.Before)
+	findOpts.BypassDocumentValidation = replaceOpts.BypassDocumentValidation
+	findOpts.Collation = replaceOpts.Collation
+	findOpts.Comment = replaceOpts.Comment
+	findOpts.Hint = replaceOpts.Hint
+	findOpts.Upsert = replaceOpts.Upsert
+	findOpts.Let = replaceOpts.Let
+	return findOpts
+}
+
+func deleteToFindOneAndDeleteOptions(opts ...*options.DeleteOptions) *options.FindOneAndDeleteOptions {
+	deleteOpts := options.MergeDeleteOptions(opts...)
+	findOpts := options.FindOneAndDelete()
+	findOpts.Collation = deleteOpts.Collation
+	findOpts.Comment = deleteOpts.Comment
+	findOpts.Hint = deleteOpts.Hint
+	findOpts.Let = deleteOpts.Let
+	return findOpts
+}
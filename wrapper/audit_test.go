@@ -0,0 +1,259 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type myContextLogger struct {
+	*myLogger
+	ctx context.Context
+}
+
+func (l *myContextLogger) Context() context.Context {
+	return l.ctx
+}
+
+func newAuditedCollection(t *mtest.T) *Collection[account] {
+	return NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetAudit(true))
+}
+
+func Test_documentIdOf(t *testing.T) {
+	col := &Collection[account]{}
+	assert.Equal(t, int32(1), col.documentIdOf(bson.M{"_id": 1, "account_id": 2}))
+	assert.Equal(t, int32(1), col.documentIdOf(struct {
+		Id int `bson:"_id"`
+	}{Id: 1}))
+	assert.Nil(t, col.documentIdOf(bson.M{"_id": bson.M{"$in": bson.A{1, 2}}}))
+	assert.Nil(t, col.documentIdOf(bson.M{"account_id": 2}))
+	assert.Nil(t, col.documentIdOf(nil))
+}
+
+func Test_upsertIdOf(t *testing.T) {
+	col := &Collection[account]{}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "limit", Value: 100}}}}
+
+	t.Run("not upsert", func(t *testing.T) {
+		_, id, ok := col.upsertIdOf(bson.M{"account_id": 2}, update, false)
+		assert.True(t, ok)
+		assert.Nil(t, id)
+	})
+
+	t.Run("id of the filter", func(t *testing.T) {
+		_, id, ok := col.upsertIdOf(bson.M{"_id": 1}, update, true)
+		assert.True(t, ok)
+		assert.Equal(t, int32(1), id)
+	})
+
+	t.Run("generated id", func(t *testing.T) {
+		upsertUpdate, id, ok := col.upsertIdOf(bson.M{"account_id": 2}, update, true)
+		assert.True(t, ok)
+		assert.IsType(t, primitive.ObjectID{}, id)
+		setOnInsert, _ := lookup(upsertUpdate.(bson.D), "$setOnInsert")
+		assert.Equal(t, bson.D{{Key: "_id", Value: id}}, setOnInsert)
+	})
+
+	t.Run("filter on _id that is not a single id", func(t *testing.T) {
+		_, _, ok := col.upsertIdOf(bson.M{"$and": bson.A{bson.M{"_id": bson.M{"$gt": 1}}}}, update, true)
+		assert.False(t, ok)
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		_, _, ok := col.upsertIdOf(bson.M{"account_id": 2}, bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "limit", Value: 100}}}}}, true)
+		assert.False(t, ok)
+	})
+}
+
+func Test_succeeded(t *testing.T) {
+	items := []int{0, 1, 2, 3}
+	bulkException := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Index: 1}}}}
+	unordered := false
+
+	assert.Equal(t, items, succeeded(items, nil, nil))
+	assert.Equal(t, []int{0}, succeeded(items, bulkException, nil))
+	assert.Equal(t, []int{0, 2, 3}, succeeded(items, bulkException, &unordered))
+	assert.Empty(t, succeeded(items, errors.New("network"), nil))
+}
+
+func Test_Audit(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("insert one", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		ctx := WithRequestId(WithActor(context.Background(), "admin"), "req-1")
+
+		_, err := col.InsertOne(&myContextLogger{logger, ctx}, bson.M{"_id": 1, "account_id": 2})
+		assert.NoError(t, err)
+
+		assert.Equal(t, t.Coll.Name(), t.GetStartedEvent().Command.Lookup("insert").StringValue())
+		audit := t.GetStartedEvent().Command
+		assert.Equal(t, t.Coll.Name()+"_audit", audit.Lookup("insert").StringValue())
+		entry := audit.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, AuditInsert, entry.Lookup("operation").StringValue())
+		assert.Equal(t, "admin", entry.Lookup("actor").StringValue())
+		assert.Equal(t, "req-1", entry.Lookup("request_id").StringValue())
+		assert.Equal(t, int32(2), entry.Lookup("after", "account_id").Int32())
+	})
+
+	mt.Run("update not matched is not audited", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+
+		_, err := col.UpdateOne(logger, bson.M{"_id": 1}, bson.M{"$set": bson.M{"account_id": 3}})
+		assert.True(t, errorType.IsNotFoundErr(err))
+		t.GetStartedEvent()
+		assert.Nil(t, t.GetStartedEvent())
+	})
+
+	mt.Run("soft delete is audited as delete", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetAudit(true).SetSoftDelete(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: 7}, {Key: "account_id", Value: 2}}}), mtest.CreateSuccessResponse())
+
+		result, err := col.DeleteOne(logger, bson.M{"account_id": 2})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.DeletedCount)
+
+		assert.Equal(t, t.Coll.Name(), t.GetStartedEvent().Command.Lookup("findAndModify").StringValue())
+		entry := t.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, AuditDelete, entry.Lookup("operation").StringValue())
+		assert.Equal(t, int32(7), entry.Lookup("document_id").Int32())
+	})
+
+	mt.Run("update one by another field is audited with the id and the image before", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: 7}, {Key: "account_id", Value: 2}}}), mtest.CreateSuccessResponse())
+
+		result, err := col.UpdateOne(logger, bson.M{"account_id": 2}, bson.M{"$set": bson.M{"account_id": 3}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.MatchedCount)
+
+		update := t.GetStartedEvent().Command
+		assert.Equal(t, t.Coll.Name(), update.Lookup("findAndModify").StringValue())
+		assert.False(t, update.Lookup("new").Boolean())
+		entry := t.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, int32(7), entry.Lookup("document_id").Int32())
+		assert.Equal(t, int32(2), entry.Lookup("before", "account_id").Int32())
+	})
+
+	mt.Run("upsert by another field is audited with the id it inserts", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}), mtest.CreateSuccessResponse())
+
+		result, err := col.UpsertOne(logger, bson.M{"account_id": 2}, bson.M{"$set": bson.M{"limit": 100}})
+		assert.NoError(t, err)
+		assert.Equal(t, UpsertInserted, result.Outcome)
+
+		update := t.GetStartedEvent().Command
+		id := update.Lookup("update", "$setOnInsert", "_id").ObjectID()
+		assert.Equal(t, id, result.UpsertedID)
+		entry := t.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, id, entry.Lookup("document_id").ObjectID())
+	})
+
+	mt.Run("update many is audited by document", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(
+			mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch, bson.D{{Key: "_id", Value: 7}}, bson.D{{Key: "_id", Value: 8}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		_, err := col.UpdateMany(logger, bson.M{"account_id": 2}, bson.M{"$set": bson.M{"limit": 100}})
+		assert.NoError(t, err)
+
+		find := t.GetStartedEvent().Command
+		assert.Equal(t, int32(1), find.Lookup("projection", "_id").Int32())
+		assert.Equal(t, "update", t.GetStartedEvent().CommandName)
+		entries := t.GetStartedEvent().Command.Lookup("documents").Array()
+		assert.Equal(t, int32(7), entries.Index(0).Value().Document().Lookup("document_id").Int32())
+		assert.Equal(t, int32(8), entries.Index(1).Value().Document().Lookup("document_id").Int32())
+	})
+
+	auditFailure := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Message: "unauthorized"})
+
+	mt.Run("audit failure outside a transaction is reported", func(t *mtest.T) {
+		var reported []AuditEntry
+		var reportedErr error
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetAudit(true).
+			SetOnAuditError(func(ctx context.Context, entries []AuditEntry, err error) {
+				reported, reportedErr = entries, err
+			}))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: 1}}}), auditFailure)
+
+		result, err := col.UpdateOne(logger, bson.M{"_id": 1}, bson.M{"$set": bson.M{"account_id": 3}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.MatchedCount)
+
+		assert.True(t, errorType.IsAuditErr(reportedErr))
+		assert.Len(t, reported, 1)
+		assert.Equal(t, AuditUpdate, reported[0].Operation)
+		assert.Equal(t, t.Coll.Name(), reported[0].Collection)
+	})
+
+	mt.Run("audit failure outside a transaction is logged by default", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(), auditFailure)
+
+		id, err := col.InsertOne(logger, bson.M{"_id": 1, "account_id": 2})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), id)
+	})
+
+	mt.Run("audit failure in a transaction aborts it", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetAudit(true).
+			SetOnAuditError(func(ctx context.Context, entries []AuditEntry, err error) {
+				t.Fatal("an audit failure in a transaction must not be reported")
+			}))
+		session, err := t.Client.StartSession()
+		assert.NoError(t, err)
+		defer session.EndSession(context.Background())
+		assert.NoError(t, session.StartTransaction())
+		sessCtx := mongo.NewSessionContext(context.Background(), session)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: 1}, {Key: "account_id", Value: 2}}}), auditFailure)
+
+		var data account
+		err = col.FindOneAndModifyWithTrx(logger, &data, bson.M{"_id": 1}, bson.M{"$set": bson.M{"account_id": 2}}, &sessCtx)
+		assert.True(t, errorType.IsAuditErr(err))
+		assert.True(t, errorType.IsTransient(err))
+		assert.Equal(t, 2, data.AccountId)
+
+		var queryErr errorType.QueryError
+		assert.True(t, errors.As(err, &queryErr))
+		assert.Equal(t, t.Coll.Name()+"_audit", queryErr.Collection())
+		assert.Equal(t, "FindOneAndModify", queryErr.Operation())
+	})
+
+	mt.Run("history", func(t *mtest.T) {
+		col := newAuditedCollection(t)
+		t.AddMockResponses(mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name()+"_audit", mtest.FirstBatch,
+			bson.D{{Key: "collection", Value: t.Coll.Name()}, {Key: "operation", Value: AuditInsert}, {Key: "document_id", Value: 1}},
+			bson.D{{Key: "collection", Value: t.Coll.Name()}, {Key: "operation", Value: AuditUpdate}, {Key: "document_id", Value: 1}},
+		))
+
+		entries, err := col.AuditHistory(logger, 1)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, AuditUpdate, entries[1].Operation)
+
+		filter := t.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, int32(1), filter.Lookup("document_id").Int32())
+	})
+
+	mt.Run("history disabled", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		_, err := col.AuditHistory(logger, 1)
+		assert.Equal(t, errorType.AuditDisabledErr, err)
+	})
+}
@@ -0,0 +1,281 @@
+package wrapper
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BulkWriter buffers write models of a Collection and writes them with BulkWrite
+// when the buffer reaches MaxModels or MaxBytes, every FlushInterval, and on Flush and Close.
+// Close must be called to write the rest of the buffer and stop the interval flush.
+type BulkWriter[T any] struct {
+	col    *Collection[T]
+	logger Logger
+	opts   *BulkWriterOptions
+
+	mu      sync.Mutex
+	models  []mongo.WriteModel
+	bytes   int
+	closing bool
+	closed  bool
+	// inFlight counts the taken batches that are not flushed yet, and idle is closed when it is 0.
+	inFlight int
+	idle     chan struct{}
+	// lastTurn is closed when the last taken batch has a flush slot, so that batches take slots in the order they were taken.
+	lastTurn chan struct{}
+
+	flushes  chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func (col *Collection[T]) NewBulkWriter(logger Logger, opts ...*BulkWriterOptions) *BulkWriter[T] {
+	merged := mergeBulkWriterOptions(opts...)
+	writer := &BulkWriter[T]{
+		col:      col,
+		logger:   logger,
+		opts:     merged,
+		idle:     make(chan struct{}),
+		lastTurn: make(chan struct{}),
+		flushes:  make(chan struct{}, *merged.MaxConcurrentFlushes),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	close(writer.idle)
+	close(writer.lastTurn)
+	if *merged.FlushInterval > 0 {
+		go writer.flushEvery(*merged.FlushInterval)
+	} else {
+		close(writer.stopped)
+	}
+	return writer
+}
+
+func (w *BulkWriter[T]) Insert(document T) error {
+	return w.Add(mongo.NewInsertOneModel().SetDocument(document))
+}
+
+func (w *BulkWriter[T]) Update(filter interface{}, update interface{}) error {
+	return w.Add(mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+}
+
+func (w *BulkWriter[T]) Upsert(filter interface{}, update interface{}) error {
+	return w.Add(mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+}
+
+func (w *BulkWriter[T]) Replace(filter interface{}, document T) error {
+	return w.Add(mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(document))
+}
+
+func (w *BulkWriter[T]) Delete(filter interface{}) error {
+	return w.Add(mongo.NewDeleteOneModel().SetFilter(filter))
+}
+
+// Add buffers the model, and starts a flush if the buffer is full.
+// It blocks while MaxConcurrentFlushes flushes are running.
+func (w *BulkWriter[T]) Add(model mongo.WriteModel) error {
+	w.mu.Lock()
+	if w.closing {
+		w.mu.Unlock()
+		return errorType.BulkWriterClosedErr
+	}
+	w.models = append(w.models, model)
+	w.bytes += modelSize(model)
+	var b *batch
+	if len(w.models) >= *w.opts.MaxModels || w.bytes >= *w.opts.MaxBytes {
+		b = w.take()
+	}
+	w.mu.Unlock()
+
+	if b != nil {
+		w.acquire(b)
+		go w.flush(b)
+	}
+	return nil
+}
+
+// Flush writes the buffered models and waits until every running flush is done or ctx is done.
+// If ctx is done first, the models are still written in the background after the batches taken before them.
+func (w *BulkWriter[T]) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	b := w.take()
+	w.mu.Unlock()
+
+	var err error
+	if b != nil {
+		result := make(chan error, 1)
+		go func() {
+			w.acquire(b)
+			result <- w.flush(b)
+		}()
+		select {
+		case err = <-result:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if waitErr := w.wait(ctx); waitErr != nil {
+		return waitErr
+	}
+	return err
+}
+
+// Close stops accepting models, stops the interval flush and flushes the buffer.
+// If the flush does not finish, such as when ctx is done, Close can be called again to wait for it.
+func (w *BulkWriter[T]) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closing = true
+	w.mu.Unlock()
+
+	w.stopOnce.Do(func() { close(w.stop) })
+	select {
+	case <-w.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := w.Flush(ctx); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *BulkWriter[T]) flushEvery(interval time.Duration) {
+	defer close(w.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			b := w.take()
+			w.mu.Unlock()
+			if b != nil {
+				w.acquire(b)
+				go w.flush(b)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// batch is a set of models taken from the buffer. turn is closed once it has a flush slot, and the next batch waits for
+// prev, the turn of the batch taken before it.
+type batch struct {
+	models []mongo.WriteModel
+	prev   chan struct{}
+	turn   chan struct{}
+}
+
+// take empties the buffer and returns its models as the next batch. It must be called with mu locked.
+// The caller must acquire a flush slot for the batch and flush it, and it is counted as in flight until then.
+func (w *BulkWriter[T]) take() *batch {
+	if len(w.models) == 0 {
+		return nil
+	}
+	if w.inFlight == 0 {
+		w.idle = make(chan struct{})
+	}
+	w.inFlight++
+	b := &batch{models: w.models, prev: w.lastTurn, turn: make(chan struct{})}
+	w.lastTurn = b.turn
+	w.models = nil
+	w.bytes = 0
+	return b
+}
+
+// acquire blocks until the batches taken before b have flush slots, and then until a slot is free for b.
+// So batches start in the order they were taken, and with a single slot they are also applied in that order.
+func (w *BulkWriter[T]) acquire(b *batch) {
+	<-b.prev
+	w.flushes <- struct{}{}
+	close(b.turn)
+}
+
+// flush writes a batch that has a flush slot, and then frees the slot and marks the batch as flushed.
+func (w *BulkWriter[T]) flush(b *batch) error {
+	defer w.done()
+	defer func() { <-w.flushes }()
+	return w.write(context.Background(), b.models)
+}
+
+// done marks a taken batch as flushed.
+func (w *BulkWriter[T]) done() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.inFlight--
+	if w.inFlight == 0 {
+		close(w.idle)
+	}
+}
+
+func (w *BulkWriter[T]) write(ctx context.Context, models []mongo.WriteModel) error {
+	ctx, ctxCancel := context.WithTimeout(ctx, w.logger.GetTimeoutDuration())
+	defer ctxCancel()
+	result, err := w.col.bulkWrite(w.logger, ctx, models, options.BulkWrite().SetOrdered(*w.opts.Ordered))
+	if err != nil {
+		err = w.col.withWriteOperation(errorType.ParseAndReturnBulkError(err, w.col.Name(), models, nil), "BulkWrite")
+		if w.opts.OnError != nil {
+			w.opts.OnError(models, err)
+		}
+		return err
+	}
+	if w.opts.OnFlush != nil {
+		w.opts.OnFlush(models, result)
+	}
+	return nil
+}
+
+func (w *BulkWriter[T]) wait(ctx context.Context) error {
+	w.mu.Lock()
+	idle := w.idle
+	w.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// modelSize returns the approximate bson size of the documents of a model.
+func modelSize(model mongo.WriteModel) int {
+	switch m := model.(type) {
+	case *mongo.InsertOneModel:
+		return len(toRaw(m.Document))
+	case *mongo.UpdateOneModel:
+		return len(toRaw(m.Filter)) + rawValueSize(m.Update)
+	case *mongo.UpdateManyModel:
+		return len(toRaw(m.Filter)) + rawValueSize(m.Update)
+	case *mongo.ReplaceOneModel:
+		return len(toRaw(m.Filter)) + len(toRaw(m.Replacement))
+	case *mongo.DeleteOneModel:
+		return len(toRaw(m.Filter))
+	case *mongo.DeleteManyModel:
+		return len(toRaw(m.Filter))
+	}
+	return 0
+}
+
+// rawValueSize returns the bson size of an update document or pipeline.
+func rawValueSize(v interface{}) int {
+	_, data, err := bson.MarshalValue(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
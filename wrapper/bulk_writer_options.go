@@ -0,0 +1,115 @@
+package wrapper
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultBulkWriterMaxModels            = 1000
+	defaultBulkWriterMaxBytes             = 8 * 1024 * 1024
+	defaultBulkWriterFlushInterval        = time.Second
+	defaultBulkWriterMaxConcurrentFlushes = 1
+)
+
+// BulkWriterOptions represents options that change when and how a BulkWriter flushes.
+type BulkWriterOptions struct {
+	// The number of buffered models that triggers a flush. The default value is 1000.
+	MaxModels *int
+
+	// The approximate bson size of buffered models that triggers a flush. The default value is 8MB.
+	MaxBytes *int
+
+	// The interval of flushing buffered models. Zero disables it. The default value is 1 second.
+	FlushInterval *time.Duration
+
+	// The number of flushes that may run at once. Adding a model blocks while the limit is reached.
+	// Flushes start in the order their models were added. With one, they are also applied in that order, and with more
+	// than one, a flush may be applied before the flushes that started earlier. The default value is 1.
+	MaxConcurrentFlushes *int
+
+	// If true, a flush stops at the first failed model. The default value is true.
+	Ordered *bool
+
+	// Called with the models and the result of every successful flush.
+	OnFlush func(models []mongo.WriteModel, result *mongo.BulkWriteResult)
+
+	// Called with the models and the error of every failed flush.
+	OnError func(models []mongo.WriteModel, err error)
+}
+
+func NewBulkWriterOptions() *BulkWriterOptions {
+	return &BulkWriterOptions{}
+}
+
+func (o *BulkWriterOptions) SetMaxModels(maxModels int) *BulkWriterOptions {
+	o.MaxModels = &maxModels
+	return o
+}
+
+func (o *BulkWriterOptions) SetMaxBytes(maxBytes int) *BulkWriterOptions {
+	o.MaxBytes = &maxBytes
+	return o
+}
+
+func (o *BulkWriterOptions) SetFlushInterval(interval time.Duration) *BulkWriterOptions {
+	o.FlushInterval = &interval
+	return o
+}
+
+func (o *BulkWriterOptions) SetMaxConcurrentFlushes(maxConcurrentFlushes int) *BulkWriterOptions {
+	o.MaxConcurrentFlushes = &maxConcurrentFlushes
+	return o
+}
+
+func (o *BulkWriterOptions) SetOrdered(ordered bool) *BulkWriterOptions {
+	o.Ordered = &ordered
+	return o
+}
+
+func (o *BulkWriterOptions) SetOnFlush(onFlush func(models []mongo.WriteModel, result *mongo.BulkWriteResult)) *BulkWriterOptions {
+	o.OnFlush = onFlush
+	return o
+}
+
+func (o *BulkWriterOptions) SetOnError(onError func(models []mongo.WriteModel, err error)) *BulkWriterOptions {
+	o.OnError = onError
+	return o
+}
+
+func mergeBulkWriterOptions(opts ...*BulkWriterOptions) *BulkWriterOptions {
+	merged := NewBulkWriterOptions().
+		SetMaxModels(defaultBulkWriterMaxModels).
+		SetMaxBytes(defaultBulkWriterMaxBytes).
+		SetFlushInterval(defaultBulkWriterFlushInterval).
+		SetMaxConcurrentFlushes(defaultBulkWriterMaxConcurrentFlushes).
+		SetOrdered(true)
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.MaxModels != nil && *opt.MaxModels > 0 {
+			merged.MaxModels = opt.MaxModels
+		}
+		if opt.MaxBytes != nil && *opt.MaxBytes > 0 {
+			merged.MaxBytes = opt.MaxBytes
+		}
+		if opt.FlushInterval != nil {
+			merged.FlushInterval = opt.FlushInterval
+		}
+		if opt.MaxConcurrentFlushes != nil && *opt.MaxConcurrentFlushes > 0 {
+			merged.MaxConcurrentFlushes = opt.MaxConcurrentFlushes
+		}
+		if opt.Ordered != nil {
+			merged.Ordered = opt.Ordered
+		}
+		if opt.OnFlush != nil {
+			merged.OnFlush = opt.OnFlush
+		}
+		if opt.OnError != nil {
+			merged.OnError = opt.OnError
+		}
+	}
+	return merged
+}
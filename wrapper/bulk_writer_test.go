@@ -0,0 +1,150 @@
+package wrapper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_mergeBulkWriterOptions(t *testing.T) {
+	merged := mergeBulkWriterOptions(NewBulkWriterOptions().SetMaxModels(10).SetMaxConcurrentFlushes(0))
+	assert.Equal(t, 10, *merged.MaxModels)
+	assert.Equal(t, defaultBulkWriterMaxBytes, *merged.MaxBytes)
+	assert.Equal(t, defaultBulkWriterMaxConcurrentFlushes, *merged.MaxConcurrentFlushes)
+	assert.True(t, *merged.Ordered)
+}
+
+func Test_BulkWriter(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("flush on size and close", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		var mu sync.Mutex
+		var flushed []int
+		writer := col.NewBulkWriter(logger, NewBulkWriterOptions().SetMaxModels(2).SetFlushInterval(0).
+			SetOnFlush(func(models []mongo.WriteModel, result *mongo.BulkWriteResult) {
+				mu.Lock()
+				defer mu.Unlock()
+				flushed = append(flushed, len(models))
+			}))
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, writer.Insert(account{AccountId: i}))
+		}
+		assert.NoError(t, writer.Close(context.Background()))
+		assert.Equal(t, []int{2, 1}, flushed)
+		assert.Equal(t, errorType.BulkWriterClosedErr, writer.Insert(account{}))
+	})
+
+	mt.Run("flush on interval", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		flushed := make(chan int, 1)
+		writer := col.NewBulkWriter(logger, NewBulkWriterOptions().SetFlushInterval(10*time.Millisecond).
+			SetOnFlush(func(models []mongo.WriteModel, result *mongo.BulkWriteResult) {
+				flushed <- len(models)
+			}))
+		defer writer.Close(context.Background())
+
+		assert.NoError(t, writer.Delete(bson.M{"account_id": 1}))
+		select {
+		case n := <-flushed:
+			assert.Equal(t, 1, n)
+		case <-time.After(time.Second):
+			t.Fatal("interval flush did not run")
+		}
+	})
+
+	mt.Run("flush error", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+
+		var failed []mongo.WriteModel
+		writer := col.NewBulkWriter(logger, NewBulkWriterOptions().SetFlushInterval(0).
+			SetOnError(func(models []mongo.WriteModel, err error) {
+				failed = models
+			}))
+		assert.NoError(t, writer.Update(bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 1}}))
+		assert.Error(t, writer.Flush(context.Background()))
+		assert.Len(t, failed, 1)
+		assert.NoError(t, writer.Close(context.Background()))
+	})
+
+	mt.Run("flushes in the order of adds", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		const count = 200
+		for i := 0; i < count; i++ {
+			t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		}
+
+		var mu sync.Mutex
+		var flushed []int
+		writer := col.NewBulkWriter(logger, NewBulkWriterOptions().SetMaxModels(3).SetFlushInterval(time.Millisecond).
+			SetOnFlush(func(models []mongo.WriteModel, result *mongo.BulkWriteResult) {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, model := range models {
+					flushed = append(flushed, model.(*mongo.InsertOneModel).Document.(account).AccountId)
+				}
+			}))
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				assert.NoError(t, writer.Insert(account{AccountId: i}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < count/4; i++ {
+				assert.NoError(t, writer.Flush(context.Background()))
+			}
+		}()
+		wg.Wait()
+		assert.NoError(t, writer.Close(context.Background()))
+
+		expected := make([]int, count)
+		for i := range expected {
+			expected[i] = i
+		}
+		assert.Equal(t, expected, flushed)
+	})
+
+	mt.Run("close again after context error", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		var flushed int
+		writer := col.NewBulkWriter(logger, NewBulkWriterOptions().SetFlushInterval(0).SetMaxConcurrentFlushes(1).
+			SetOnFlush(func(models []mongo.WriteModel, result *mongo.BulkWriteResult) {
+				flushed += len(models)
+			}))
+		assert.NoError(t, writer.Insert(account{AccountId: 1}))
+
+		// every flush slot is taken, so the flush of Close cannot start before ctx is done
+		writer.flushes <- struct{}{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, context.Canceled, writer.Close(ctx))
+		assert.Equal(t, errorType.BulkWriterClosedErr, writer.Insert(account{}))
+		<-writer.flushes
+
+		assert.NoError(t, writer.Close(context.Background()))
+		assert.Equal(t, 1, flushed)
+		assert.NoError(t, writer.Close(context.Background()))
+	})
+}
@@ -0,0 +1,301 @@
+package wrapper
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionOptions represents options that change the behavior of a Collection.
+type CollectionOptions struct {
+	// If true, DeleteOne, DeleteMany and FindOneAndDelete mark documents as deleted instead of removing them,
+	// and find, count, aggregate, update and replace queries exclude marked documents. The default value is false.
+	SoftDelete *bool
+
+	// The field that holds the deletion time of soft-deleted documents. The default value is "deletedAt".
+	SoftDeleteField *string
+
+	// If true, inserts and upserts set the creation time and every write sets the update time. The default value is false.
+	Timestamps *bool
+
+	// The field that holds the creation time. The default value is the field of T tagged `mongo:"createdAt"`, or "createdAt".
+	CreatedAtField *string
+
+	// The field that holds the update time. The default value is the field of T tagged `mongo:"updatedAt"`, or "updatedAt".
+	UpdatedAtField *string
+
+	// If true, replacements must carry the current version of the document and every write increments it.
+	// The default value is false.
+	OptimisticLock *bool
+
+	// The field that holds the document version. The default value is the field of T tagged `mongo:"version"`, or "version".
+	VersionField *string
+
+	// If true, every write is recorded in the audit collection with the id of the document it wrote. The default value is false.
+	// UpdateOne, ReplaceOne and DeleteOne are written with findAndModify to record the document and its image before
+	// the write, so a matched document counts as modified. UpdateMany and DeleteMany look up the ids they match before
+	// the write. The models of BulkWrite are recorded with the id of their filter only if it matches a single _id.
+	Audit *bool
+
+	// The collection in the same database that audit entries are written to. The default value is "<collection>_audit".
+	AuditCollection *string
+
+	// The function that is called with the audit entries that could not be written outside a transaction, where the
+	// audited write has been applied and the operation succeeds. In a transaction, the operation returns the audit error
+	// instead, which aborts the transaction. The default value logs the error with the standard logger.
+	OnAuditError func(ctx context.Context, entries []AuditEntry, err error)
+
+	// If true, slow finds, aggregations, counts, updates and deletes are explained with the executionStats verbosity
+	// and the plan is attached to the slow query event. The default value is false.
+	ExplainSlowQueries *bool
+
+	// The fraction of slow queries that are explained. The default value is 1.
+	ExplainSampleRate *float64
+
+	// The maximum number of explains per minute. The default value is 10.
+	ExplainLimit *int
+
+	// The statistics that every query of the collection is recorded in by its shape. The default value is nil,
+	// which records nothing.
+	QueryStats *QueryStats
+
+	// The sampler that limits the slow queries reported to the logger. The default value is nil, which reports every slow query.
+	SlowQuerySampler *SlowQuerySampler
+
+	// Slow queries that took this many times the slow query duration or longer have SeverityCritical, which the sampler does not suppress.
+	// The default value is 0, which never escalates.
+	SlowQueryCriticalFactor *float64
+
+	// If true, the commands of every query are measured by CommandMonitor, which the client must be connected with,
+	// and a query is slow by the time of its commands instead of the time around the driver call.
+	// Finds and aggregations are measured until their cursor is decoded. The default value is false.
+	MeasureCommands *bool
+
+	// The registry that the collection and the documents stamped by the wrapper are marshaled with.
+	// The default value is the registry of the client if it was connected with Connect, or the default registry.
+	Registry *bsoncodec.Registry
+}
+
+func NewCollectionOptions() *CollectionOptions {
+	return &CollectionOptions{}
+}
+
+func (o *CollectionOptions) SetSoftDelete(softDelete bool) *CollectionOptions {
+	o.SoftDelete = &softDelete
+	return o
+}
+
+func (o *CollectionOptions) SetSoftDeleteField(field string) *CollectionOptions {
+	o.SoftDeleteField = &field
+	return o
+}
+
+func (o *CollectionOptions) SetTimestamps(timestamps bool) *CollectionOptions {
+	o.Timestamps = &timestamps
+	return o
+}
+
+func (o *CollectionOptions) SetCreatedAtField(field string) *CollectionOptions {
+	o.CreatedAtField = &field
+	return o
+}
+
+func (o *CollectionOptions) SetUpdatedAtField(field string) *CollectionOptions {
+	o.UpdatedAtField = &field
+	return o
+}
+
+func (o *CollectionOptions) SetOptimisticLock(optimisticLock bool) *CollectionOptions {
+	o.OptimisticLock = &optimisticLock
+	return o
+}
+
+func (o *CollectionOptions) SetVersionField(field string) *CollectionOptions {
+	o.VersionField = &field
+	return o
+}
+
+func (o *CollectionOptions) SetAudit(audit bool) *CollectionOptions {
+	o.Audit = &audit
+	return o
+}
+
+func (o *CollectionOptions) SetAuditCollection(collection string) *CollectionOptions {
+	o.AuditCollection = &collection
+	return o
+}
+
+func (o *CollectionOptions) SetOnAuditError(onAuditError func(ctx context.Context, entries []AuditEntry, err error)) *CollectionOptions {
+	o.OnAuditError = onAuditError
+	return o
+}
+
+func (o *CollectionOptions) SetExplainSlowQueries(explain bool) *CollectionOptions {
+	o.ExplainSlowQueries = &explain
+	return o
+}
+
+func (o *CollectionOptions) SetExplainSampleRate(rate float64) *CollectionOptions {
+	o.ExplainSampleRate = &rate
+	return o
+}
+
+func (o *CollectionOptions) SetExplainLimit(limit int) *CollectionOptions {
+	o.ExplainLimit = &limit
+	return o
+}
+
+func (o *CollectionOptions) SetQueryStats(stats *QueryStats) *CollectionOptions {
+	o.QueryStats = stats
+	return o
+}
+
+func (o *CollectionOptions) SetSlowQuerySampler(sampler *SlowQuerySampler) *CollectionOptions {
+	o.SlowQuerySampler = sampler
+	return o
+}
+
+func (o *CollectionOptions) SetSlowQueryCriticalFactor(factor float64) *CollectionOptions {
+	o.SlowQueryCriticalFactor = &factor
+	return o
+}
+
+func (o *CollectionOptions) SetMeasureCommands(measure bool) *CollectionOptions {
+	o.MeasureCommands = &measure
+	return o
+}
+
+func (o *CollectionOptions) SetRegistry(registry *bsoncodec.Registry) *CollectionOptions {
+	o.Registry = registry
+	return o
+}
+
+func mergeCollectionOptions(opts ...*CollectionOptions) *CollectionOptions {
+	merged := NewCollectionOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.SoftDelete != nil {
+			merged.SoftDelete = opt.SoftDelete
+		}
+		if opt.SoftDeleteField != nil {
+			merged.SoftDeleteField = opt.SoftDeleteField
+		}
+		if opt.Timestamps != nil {
+			merged.Timestamps = opt.Timestamps
+		}
+		if opt.CreatedAtField != nil {
+			merged.CreatedAtField = opt.CreatedAtField
+		}
+		if opt.UpdatedAtField != nil {
+			merged.UpdatedAtField = opt.UpdatedAtField
+		}
+		if opt.OptimisticLock != nil {
+			merged.OptimisticLock = opt.OptimisticLock
+		}
+		if opt.VersionField != nil {
+			merged.VersionField = opt.VersionField
+		}
+		if opt.Audit != nil {
+			merged.Audit = opt.Audit
+		}
+		if opt.AuditCollection != nil {
+			merged.AuditCollection = opt.AuditCollection
+		}
+		if opt.OnAuditError != nil {
+			merged.OnAuditError = opt.OnAuditError
+		}
+		if opt.ExplainSlowQueries != nil {
+			merged.ExplainSlowQueries = opt.ExplainSlowQueries
+		}
+		if opt.ExplainSampleRate != nil {
+			merged.ExplainSampleRate = opt.ExplainSampleRate
+		}
+		if opt.ExplainLimit != nil {
+			merged.ExplainLimit = opt.ExplainLimit
+		}
+		if opt.QueryStats != nil {
+			merged.QueryStats = opt.QueryStats
+		}
+		if opt.SlowQuerySampler != nil {
+			merged.SlowQuerySampler = opt.SlowQuerySampler
+		}
+		if opt.SlowQueryCriticalFactor != nil {
+			merged.SlowQueryCriticalFactor = opt.SlowQueryCriticalFactor
+		}
+		if opt.MeasureCommands != nil {
+			merged.MeasureCommands = opt.MeasureCommands
+		}
+		if opt.Registry != nil {
+			merged.Registry = opt.Registry
+		}
+	}
+	return merged
+}
+
+func (col *Collection[T]) applyOptions(opts *CollectionOptions) {
+	if opts.Registry != nil {
+		col.registry = opts.Registry
+		if col.Collection != nil {
+			col.Collection, _ = col.Collection.Clone(options.Collection().SetRegistry(opts.Registry))
+		}
+	}
+
+	if opts.SoftDelete != nil && *opts.SoftDelete {
+		col.softDeleteField = defaultSoftDeleteField
+		if opts.SoftDeleteField != nil {
+			col.softDeleteField = *opts.SoftDeleteField
+		}
+	}
+
+	if opts.Timestamps != nil && *opts.Timestamps {
+		col.createdAtField = fieldNameOf[T](opts.CreatedAtField, createdAtTag, defaultCreatedAtField)
+		col.updatedAtField = fieldNameOf[T](opts.UpdatedAtField, updatedAtTag, defaultUpdatedAtField)
+	}
+
+	if opts.OptimisticLock != nil && *opts.OptimisticLock {
+		col.versionField = fieldNameOf[T](opts.VersionField, versionTag, defaultVersionField)
+	}
+
+	if opts.Audit != nil && *opts.Audit {
+		auditCollection := col.Name() + defaultAuditCollectionSuffix
+		if opts.AuditCollection != nil {
+			auditCollection = *opts.AuditCollection
+		}
+		col.auditCollection = col.Database().Collection(auditCollection)
+		col.onAuditError = logAuditError
+		if opts.OnAuditError != nil {
+			col.onAuditError = opts.OnAuditError
+		}
+	}
+
+	if opts.ExplainSlowQueries != nil && *opts.ExplainSlowQueries {
+		col.explainer = &explainer{sampleRate: defaultExplainSampleRate, limit: defaultExplainLimit}
+		if opts.ExplainSampleRate != nil {
+			col.explainer.sampleRate = *opts.ExplainSampleRate
+		}
+		if opts.ExplainLimit != nil {
+			col.explainer.limit = *opts.ExplainLimit
+		}
+	}
+
+	col.queryStats = opts.QueryStats
+	col.sampler = opts.SlowQuerySampler
+	if opts.SlowQueryCriticalFactor != nil {
+		col.criticalFactor = *opts.SlowQueryCriticalFactor
+	}
+	col.measureCommands = opts.MeasureCommands != nil && *opts.MeasureCommands
+}
+
+// fieldNameOf returns the configured field name, the bson name of the field of T carrying the mongo tag, or the default.
+func fieldNameOf[T any](configured *string, tag, defaultName string) string {
+	if configured != nil {
+		return *configured
+	}
+	if name := taggedFieldName[T](tag); name != "" {
+		return name
+	}
+	return defaultName
+}
@@ -0,0 +1,153 @@
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CommandTiming is the time of the commands a query sent, measured by CommandMonitor.
+type CommandTiming struct {
+	// Server is the sum of the durations of the commands from sending them to their replies,
+	// which is the time spent on the server and the network.
+	Server   time.Duration
+	Commands int
+	// GetMores is the number of getMore commands that fetched the batches of the cursor.
+	GetMores int
+	// CheckoutWait is the time from the start of the query to sending its first command,
+	// which is mostly server selection and waiting for a connection from the pool.
+	CheckoutWait time.Duration
+}
+
+func (t *CommandTiming) String() string {
+	return fmt.Sprintf("server: %v, commands: %d, getMores: %d, checkout wait: %v", t.Server, t.Commands, t.GetMores, t.CheckoutWait)
+}
+
+// CommandMonitor returns a command monitor that measures the commands of the queries of collections created with
+// SetMeasureCommands, and then calls next if it is not nil. The client must be connected with it:
+//
+//	clientOpt := options.Client().ApplyURI(uri).SetMonitor(wrapper.CommandMonitor(nil))
+func CommandMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if trace := traceOf(ctx); trace != nil {
+				trace.started(e)
+			}
+			if next.Started != nil {
+				next.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			if trace := traceOf(ctx); trace != nil {
+				trace.finished(e.CommandFinishedEvent)
+			}
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			if trace := traceOf(ctx); trace != nil {
+				trace.finished(e.CommandFinishedEvent)
+			}
+			if next.Failed != nil {
+				next.Failed(ctx, e)
+			}
+		},
+	}
+}
+
+// commandTrace collects the commands of a query from the context they were sent with.
+type commandTrace struct {
+	mu           sync.Mutex
+	firstStarted time.Time
+	timing       CommandTiming
+}
+
+type commandTraceKey struct{}
+
+func traceOf(ctx context.Context) *commandTrace {
+	if ctx == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(commandTraceKey{}).(*commandTrace)
+	return trace
+}
+
+func (t *commandTrace) started(e *event.CommandStartedEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstStarted.IsZero() {
+		t.firstStarted = time.Now()
+	}
+	t.timing.Commands++
+	if e.CommandName == "getMore" {
+		t.timing.GetMores++
+	}
+}
+
+func (t *commandTrace) finished(e event.CommandFinishedEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timing.Server += time.Duration(e.DurationNanos)
+}
+
+// timingSince returns the timing of the query started at startTime, or nil if no command was measured
+// because the client is not connected with CommandMonitor.
+func (t *commandTrace) timingSince(startTime time.Time) *CommandTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timing.Commands == 0 {
+		return nil
+	}
+	timing := t.timing
+	timing.CheckoutWait = t.firstStarted.Sub(startTime)
+	return &timing
+}
+
+// startQuery returns the start time of a query, and a context that traces its commands if the collection measures commands.
+func (col *Collection[T]) startQuery(ctx context.Context) (context.Context, time.Time) {
+	if col.measureCommands {
+		ctx = context.WithValue(ctx, commandTraceKey{}, &commandTrace{})
+	}
+	return ctx, time.Now()
+}
+
+// queryCursor is the cursor of a find or an aggregation. If the collection measures commands,
+// the query is observed when the cursor is decoded, so that the getMore commands are measured.
+type queryCursor struct {
+	*mongo.Cursor
+	ctx     context.Context
+	observe func(err error)
+}
+
+// observeCursor observes a find or an aggregation now, or when its cursor is decoded if the collection measures commands.
+func (col *Collection[T]) observeCursor(logger Logger, ctx context.Context, startTime time.Time, threshold time.Duration, event SlowQueryEvent, cursor *mongo.Cursor, err error) (*queryCursor, error) {
+	trace := traceOf(ctx)
+	if err != nil || !col.measureCommands || trace == nil {
+		col.observe(logger, ctx, startTime, threshold, event, err)
+		return &queryCursor{Cursor: cursor}, err
+	}
+	return &queryCursor{
+		Cursor: cursor,
+		// the batches are fetched without the timeout of the query as DecodeCursor does
+		ctx:     context.WithValue(context.Background(), commandTraceKey{}, trace),
+		observe: func(err error) { col.observe(logger, ctx, startTime, threshold, event, err) },
+	}, nil
+}
+
+// decodeAll decodes the cursor of a query and observes the query if it was not yet.
+func decodeAll[T any](cursor *queryCursor) ([]T, error) {
+	if cursor.observe == nil {
+		return DecodeCursor[T](cursor.Cursor)
+	}
+	results, err := decodeCursor[T](cursor.ctx, cursor.Cursor)
+	cursor.observe(err)
+	return results, err
+}
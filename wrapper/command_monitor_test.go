@@ -0,0 +1,176 @@
+package wrapper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/address"
+	"go.mongodb.org/mongo-driver/mongo/description"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// delayedDeployment is a single server deployment that answers every command with the next reply after delay.
+type delayedDeployment struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	replies []bson.D
+}
+
+func (d *delayedDeployment) SelectServer(context.Context, description.ServerSelector) (driver.Server, error) {
+	return d, nil
+}
+func (d *delayedDeployment) Kind() description.TopologyKind { return description.Single }
+func (d *delayedDeployment) Connection(context.Context) (driver.Connection, error) {
+	return &delayedConn{deployment: d}, nil
+}
+func (d *delayedDeployment) RTTMonitor() driver.RTTMonitor { return zeroRTT{} }
+
+type delayedConn struct {
+	deployment *delayedDeployment
+}
+
+func (c *delayedConn) WriteWireMessage(context.Context, []byte) error { return nil }
+func (c *delayedConn) ReadWireMessage(_ context.Context, dst []byte) ([]byte, error) {
+	time.Sleep(c.deployment.delay)
+	c.deployment.mu.Lock()
+	defer c.deployment.mu.Unlock()
+	if len(c.deployment.replies) == 0 {
+		return dst, errors.New("no reply")
+	}
+	reply, err := bson.Marshal(c.deployment.replies[0])
+	if err != nil {
+		return dst, err
+	}
+	c.deployment.replies = c.deployment.replies[1:]
+	var index int32
+	index, dst = wiremessage.AppendHeaderStart(dst, wiremessage.NextRequestID(), 0, wiremessage.OpMsg)
+	dst = wiremessage.AppendMsgFlags(dst, 0)
+	dst = wiremessage.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, reply...)
+	return bsoncore.UpdateLength(dst, index, int32(len(dst[index:]))), nil
+}
+func (c *delayedConn) Description() description.Server {
+	return description.Server{
+		Kind:            description.Standalone,
+		WireVersion:     &description.VersionRange{Max: 17},
+		MaxDocumentSize: 16777216,
+		MaxMessageSize:  48000000,
+		MaxBatchCount:   100000,
+	}
+}
+func (c *delayedConn) Close() error               { return nil }
+func (c *delayedConn) ID() string                 { return "delayed" }
+func (c *delayedConn) ServerConnectionID() *int32 { return nil }
+func (c *delayedConn) Address() address.Address   { return "delayed:27017" }
+func (c *delayedConn) Stale() bool                { return false }
+
+type zeroRTT struct{}
+
+func (zeroRTT) EWMA() time.Duration { return 0 }
+func (zeroRTT) Min() time.Duration  { return 0 }
+func (zeroRTT) P90() time.Duration  { return 0 }
+func (zeroRTT) Stats() string       { return "" }
+
+func delayedClient(t *testing.T, deployment *delayedDeployment, monitor *event.CommandMonitor) *Client {
+	opt := options.Client().SetMonitor(monitor)
+	opt.Deployment = deployment
+	client, err := mongo.Connect(context.Background(), opt)
+	assert.NoError(t, err)
+	return &Client{Client: client}
+}
+
+func Test_MeasureCommands(t *testing.T) {
+	cursorReply := func(id int64, batch string, docs ...interface{}) bson.D {
+		return bson.D{{Key: "ok", Value: 1}, {Key: "cursor", Value: bson.D{{Key: "id", Value: id}, {Key: "ns", Value: "bank.account"}, {Key: batch, Value: append(bson.A{}, docs...)}}}}
+	}
+
+	t.Run("find and its getMore", func(t *testing.T) {
+		deployment := &delayedDeployment{delay: 10 * time.Millisecond, replies: []bson.D{
+			cursorReply(123, "firstBatch", bson.D{{Key: "account_id", Value: 1}}),
+			cursorReply(0, "nextBatch", bson.D{{Key: "account_id", Value: 2}}),
+		}}
+		var started []string
+		client := delayedClient(t, deployment, CommandMonitor(&event.CommandMonitor{
+			Started: func(_ context.Context, e *event.CommandStartedEvent) { started = append(started, e.CommandName) },
+		}))
+		stats := NewQueryStats()
+		col := NewCollection[account](client, "bank", "account", NewCollectionOptions().SetMeasureCommands(true).SetQueryStats(stats))
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+
+		accounts, err := col.FindAll(logger, bson.M{"account_id": bson.M{"$gt": 0}})
+		assert.NoError(t, err)
+		assert.Len(t, accounts, 2)
+		assert.Equal(t, []string{"find", "getMore"}, started)
+
+		event := <-logger.events
+		assert.Equal(t, 2, event.Timing.Commands)
+		assert.Equal(t, 1, event.Timing.GetMores)
+		assert.GreaterOrEqual(t, event.Timing.Server, 2*deployment.delay)
+		assert.GreaterOrEqual(t, event.Elapsed, event.Timing.Server+event.Timing.CheckoutWait)
+		assert.Contains(t, event.String(), "commands: 2, getMores: 1")
+		assert.Equal(t, event.Timing.Server, stats.Snapshot()[0].Max, "the stats record the time that decides slowness")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		deployment := &delayedDeployment{replies: []bson.D{{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}}}}
+		col := NewCollection[account](delayedClient(t, deployment, CommandMonitor(nil)), "bank", "account", NewCollectionOptions().SetMeasureCommands(true))
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+
+		_, err := col.UpdateOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 100}})
+		assert.NoError(t, err)
+		event := <-logger.events
+		assert.Equal(t, 1, event.Timing.Commands)
+		assert.Equal(t, 0, event.Timing.GetMores)
+	})
+
+	t.Run("client without the monitor", func(t *testing.T) {
+		deployment := &delayedDeployment{replies: []bson.D{cursorReply(0, "firstBatch")}}
+		col := NewCollection[account](delayedClient(t, deployment, nil), "bank", "account", NewCollectionOptions().SetMeasureCommands(true))
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+
+		_, err := col.FindAll(logger, bson.M{})
+		assert.NoError(t, err)
+		assert.Nil(t, (<-logger.events).Timing, "the wall time is used")
+	})
+
+	t.Run("not measured", func(t *testing.T) {
+		deployment := &delayedDeployment{replies: []bson.D{cursorReply(0, "firstBatch")}}
+		col := NewCollection[account](delayedClient(t, deployment, CommandMonitor(nil)), "bank", "account")
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+
+		_, err := col.FindAll(logger, bson.M{})
+		assert.NoError(t, err)
+		assert.Nil(t, (<-logger.events).Timing)
+	})
+}
+
+func Test_commandTrace(t *testing.T) {
+	trace := &commandTrace{}
+	startTime := time.Now()
+	assert.Nil(t, trace.timingSince(startTime))
+
+	trace.started(&event.CommandStartedEvent{CommandName: "aggregate"})
+	trace.finished(event.CommandFinishedEvent{DurationNanos: int64(time.Second)})
+	trace.started(&event.CommandStartedEvent{CommandName: "getMore"})
+	trace.finished(event.CommandFinishedEvent{DurationNanos: int64(time.Second)})
+	timing := trace.timingSince(startTime)
+	assert.Equal(t, 2*time.Second, timing.Server)
+	assert.Equal(t, 2, timing.Commands)
+	assert.Equal(t, 1, timing.GetMores)
+	assert.GreaterOrEqual(t, timing.CheckoutWait, time.Duration(0))
+
+	ctx := context.WithValue(context.Background(), commandTraceKey{}, trace)
+	assert.Same(t, trace, traceOf(ctx))
+	assert.Nil(t, traceOf(context.Background()))
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/kjh03160/go-mongo/errorType"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -12,6 +12,8 @@ import (
 
 type Client struct {
 	*mongo.Client
+	// registry is the registry the client was connected with, which the collections marshal documents with.
+	registry *bsoncodec.Registry
 }
 
 var MongoClient *Client
@@ -28,7 +30,7 @@ func Connect(clientOpt *options.ClientOptions) *Client {
 		panic(err)
 	}
 
-	MongoClient = &Client{Client: client}
+	MongoClient = &Client{Client: client, registry: clientOpt.Registry}
 	fmt.Println("Successfully connected and pinged.")
 	return MongoClient
 }
@@ -50,22 +52,13 @@ func (client *Client) GetCollection(databaseName, collection string) *mongo.Coll
 }
 
 func (client *Client) Transaction(sessionOpt *options.SessionOptions, trxOpt *options.TransactionOptions, function func(sessCtx mongo.SessionContext) (interface{}, error)) error {
-	if sessionOpt == nil {
-		sessionOpt = &options.SessionOptions{}
-	}
-	if trxOpt == nil {
-		trxOpt = &options.TransactionOptions{}
-	}
-
-	session, err := client.Client.StartSession(sessionOpt)
-	if err != nil {
-		return errorType.MongoClientError(err)
-	}
-	defer session.EndSession(context.Background())
+	return client.TransactionWithContext(context.Background(), sessionOpt, trxOpt, function)
+}
 
-	_, err = session.WithTransaction(context.Background(), function, trxOpt)
-	if err != nil {
-		return err
-	}
-	return nil
+// TransactionWithContext is Transaction with a parent context, so the session context carries its values such as the audit actor.
+// If ctx has a running transaction of the client, function joins it and the outermost transaction commits.
+func (client *Client) TransactionWithContext(ctx context.Context, sessionOpt *options.SessionOptions, trxOpt *options.TransactionOptions, function func(sessCtx mongo.SessionContext) (interface{}, error)) error {
+	opt := mergeTransactionOptions(&TransactionOptions{Session: sessionOpt, Transaction: trxOpt})
+	_, err := client.transaction(ctx, function, opt)
+	return err
 }
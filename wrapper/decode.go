@@ -18,9 +18,13 @@ func EvaluateAndDecodeSingleResult(result *mongo.SingleResult, v interface{}) er
 }
 
 func DecodeCursor[T any](cursor *mongo.Cursor) ([]T, error) {
-	defer cursor.Close(context.Background())
+	return decodeCursor[T](context.Background(), cursor)
+}
+
+func decodeCursor[T any](ctx context.Context, cursor *mongo.Cursor) ([]T, error) {
+	defer cursor.Close(ctx)
 	slice := []T{}
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var doc T
 		if err := cursor.Decode(&doc); err != nil {
 			return nil, err
@@ -19,32 +19,32 @@ func Test_EvaluateAndDecodeSingleResult(t *testing.T) {
 	mt.Run("success", func(t *mtest.T) {
 		col := t.Coll
 
-		expected := Account{
+		expected := account{
 			AccountId: 1,
 		}
 		t.AddMockResponses(mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, bson.D{
-			{"_id", primitive.NewObjectID()},
-			{"account_id", expected.AccountId},
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "account_id", Value: expected.AccountId},
 		}))
 
 		singleResult := col.FindOne(context.Background(), bson.M{})
 
-		var w Account
+		var w account
 		err := EvaluateAndDecodeSingleResult(singleResult, &w)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, w)
 	})
 
 	mt.Run("not found", func(t *mtest.T) {
-		singleResult := mongo.NewSingleResultFromDocument(Account{}, mongo.ErrNoDocuments, nil)
-		var w Account
+		singleResult := mongo.NewSingleResultFromDocument(account{}, mongo.ErrNoDocuments, nil)
+		var w account
 		err := EvaluateAndDecodeSingleResult(singleResult, &w)
 		assert.Error(t, err)
 	})
 
 	mt.Run("err", func(t *mtest.T) {
 		singleResult := mongo.NewSingleResultFromDocument(nil, errors.New("test"), nil)
-		var w Account
+		var w account
 		err := EvaluateAndDecodeSingleResult(singleResult, &w)
 		assert.Error(t, err)
 	})
@@ -59,19 +59,19 @@ func Test_DecodeCursor(t *testing.T) {
 
 		find := mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch,
 			bson.D{
-				{"_id", primitive.NewObjectID()},
-				{"account_id", 1},
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "account_id", Value: 1},
 			})
 		getMore := mtest.CreateCursorResponse(1, "foo.bar", mtest.NextBatch,
 			bson.D{
-				{"_id", primitive.NewObjectID()},
-				{"account_id", 2},
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "account_id", Value: 2},
 			})
 		killCursors := mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch)
 		t.AddMockResponses(find, getMore, killCursors)
 		cursor, err := col.Find(context.Background(), bson.M{})
 
-		resultSlice, err := DecodeCursor[Account](cursor)
+		resultSlice, err := DecodeCursor[account](cursor)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, resultSlice)
 	})
@@ -85,7 +85,7 @@ func Test_DecodeCursor(t *testing.T) {
 
 		cursor, err := col.Find(context.Background(), bson.M{})
 
-		resultSlice, err := DecodeCursor[Account](cursor)
+		resultSlice, err := DecodeCursor[account](cursor)
 		assert.NoError(t, err)
 		assert.Empty(t, resultSlice)
 		assert.NotNil(t, resultSlice)
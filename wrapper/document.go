@@ -0,0 +1,149 @@
+package wrapper
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// toDocument returns a copy of v as bson.D, marshaled with the registry or the default registry if it is nil.
+// It fails if v does not marshal to a bson document.
+func toDocument(registry *bsoncodec.Registry, v interface{}) (bson.D, error) {
+	if registry == nil {
+		registry = bson.DefaultRegistry
+	}
+	data, err := bson.MarshalWithRegistry(registry, v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	if err := bson.UnmarshalWithRegistry(registry, data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// toUpdate returns a copy of an update as either an update document or an aggregation pipeline.
+func toUpdate(update interface{}) (bson.D, bson.A, bool) {
+	valueType, data, err := bson.MarshalValue(update)
+	if err != nil {
+		return nil, nil, false
+	}
+	switch valueType {
+	case bsontype.EmbeddedDocument:
+		var doc bson.D
+		if err := bson.Unmarshal(data, &doc); err != nil || len(doc) == 0 || !strings.HasPrefix(doc[0].Key, "$") {
+			return nil, nil, false
+		}
+		return doc, nil, true
+	case bsontype.Array:
+		var pipeline bson.A
+		if err := (bson.RawValue{Type: valueType, Value: data}).Unmarshal(&pipeline); err != nil {
+			return nil, nil, false
+		}
+		return nil, pipeline, true
+	}
+	return nil, nil, false
+}
+
+// toRaw returns v as bson.Raw, or nil if v is not a document.
+func toRaw(v interface{}) bson.Raw {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// extJSON returns v as relaxed extended JSON, or an empty string if v is nil or cannot be marshaled.
+func extJSON(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	valueType, data, err := bson.MarshalValue(v)
+	if err != nil {
+		return ""
+	}
+	return bson.RawValue{Type: valueType, Value: data}.String()
+}
+
+func lookup(doc bson.D, key string) (interface{}, bool) {
+	for _, element := range doc {
+		if element.Key == key {
+			return element.Value, true
+		}
+	}
+	return nil, false
+}
+
+func setField(doc bson.D, key string, value interface{}) bson.D {
+	for i, element := range doc {
+		if element.Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+	return append(doc, bson.E{Key: key, Value: value})
+}
+
+// setFieldIfEmpty sets the field when it is missing, null or the zero time.
+func setFieldIfEmpty(doc bson.D, key string, value interface{}) bson.D {
+	if !isEmptyField(doc, key) {
+		return doc
+	}
+	return setField(doc, key, value)
+}
+
+// isEmptyField tells whether the field is missing, null or the zero time.
+func isEmptyField(doc bson.D, key string) bool {
+	current, ok := lookup(doc, key)
+	return !ok || current == nil || current == zeroDateTime
+}
+
+var zeroDateTime = primitive.NewDateTimeFromTime(time.Time{})
+
+// updatedFields returns the fields that the operators of an update document or the $set stages of a pipeline write.
+func updatedFields(doc bson.D, pipeline bson.A) map[string]bool {
+	fields := map[string]bool{}
+	for _, element := range doc {
+		if operands, ok := element.Value.(bson.D); ok {
+			for _, operand := range operands {
+				fields[operand.Key] = true
+			}
+		}
+	}
+	for _, stage := range pipeline {
+		stageDoc, ok := stage.(bson.D)
+		if !ok || len(stageDoc) == 0 || (stageDoc[0].Key != "$set" && stageDoc[0].Key != "$addFields") {
+			continue
+		}
+		if operands, ok := stageDoc[0].Value.(bson.D); ok {
+			for _, operand := range operands {
+				fields[operand.Key] = true
+			}
+		}
+	}
+	return fields
+}
+
+// addOperand adds the operand to the operator of an update document, creating the operator if needed.
+func addOperand(doc bson.D, operator string, operand bson.E) bson.D {
+	for i, element := range doc {
+		if element.Key != operator {
+			continue
+		}
+		if operands, ok := element.Value.(bson.D); ok {
+			doc[i].Value = append(operands, operand)
+			return doc
+		}
+	}
+	return append(doc, bson.E{Key: operator, Value: bson.D{operand}})
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,165 @@
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultExplainSampleRate = 1
+	defaultExplainLimit      = 10
+)
+
+// ExplainResult is the plan the server chose for a slow query, from explain with the executionStats verbosity.
+type ExplainResult struct {
+	WinningPlan bson.Raw
+	// Stage is the stage that reads the collection in the winning plan, such as COLLSCAN or IXSCAN.
+	Stage string
+	// IndexName is the index used by the winning plan, if any.
+	IndexName     string
+	KeysExamined  int64
+	DocsExamined  int64
+	Returned      int64
+	ExecutionTime time.Duration
+}
+
+func (r *ExplainResult) String() string {
+	stage := r.Stage
+	if r.IndexName != "" {
+		stage += " " + r.IndexName
+	}
+	return fmt.Sprintf("plan: %s, keys examined: %d, docs examined: %d, returned: %d", stage, r.KeysExamined, r.DocsExamined, r.Returned)
+}
+
+// explainer samples slow queries to explain, and allows at most limit explains per minute.
+type explainer struct {
+	sampleRate float64
+	limit      int
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+}
+
+func (e *explainer) allow() bool {
+	if e.sampleRate < 1 && rand.Float64() >= e.sampleRate {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now := time.Now(); now.Sub(e.windowStart) >= time.Minute {
+		e.windowStart, e.count = now, 0
+	}
+	if e.count >= e.limit {
+		return false
+	}
+	e.count++
+	return true
+}
+
+func explainable(operation string) bool {
+	switch operation {
+	case "findOne", "findAll", "aggregate", "countDocuments", "updateOne", "updateMany", "deleteOne", "deleteMany":
+		return true
+	}
+	return false
+}
+
+// explain runs explain for the query of the event, and reports the event with the result.
+// It does not run in the session of the query, and the event is reported without the plan if explain fails.
+func (col *Collection[T]) explain(logger Logger, event SlowQueryEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	defer cancel()
+	command := bson.D{{Key: "explain", Value: explainCommandOf(col.Name(), event)}, {Key: "verbosity", Value: "executionStats"}}
+	var result bson.Raw
+	if err := col.Database().RunCommand(ctx, command).Decode(&result); err == nil {
+		event.Explain = explainResultOf(result)
+	}
+	reportSlowQuery(logger, event)
+}
+
+// explainCommandOf returns the command that runs the query of the event.
+func explainCommandOf(collection string, event SlowQueryEvent) bson.D {
+	filter := event.Filter
+	if filter == nil {
+		filter = bson.D{}
+	}
+	switch event.Operation {
+	case "findOne", "findAll":
+		command := bson.D{{Key: "find", Value: collection}, {Key: "filter", Value: filter}}
+		if event.sort != nil {
+			command = append(command, bson.E{Key: "sort", Value: event.sort})
+		}
+		if event.hint != nil {
+			command = append(command, bson.E{Key: "hint", Value: event.hint})
+		}
+		if event.Operation == "findOne" {
+			command = append(command, bson.E{Key: "limit", Value: 1})
+		}
+		return command
+	case "aggregate":
+		return bson.D{{Key: "aggregate", Value: collection}, {Key: "pipeline", Value: filter}, {Key: "cursor", Value: bson.D{}}}
+	case "countDocuments":
+		return bson.D{{Key: "count", Value: collection}, {Key: "query", Value: filter}}
+	case "updateOne", "updateMany":
+		update := bson.D{{Key: "q", Value: filter}, {Key: "u", Value: event.Update}, {Key: "multi", Value: event.Operation == "updateMany"}}
+		return bson.D{{Key: "update", Value: collection}, {Key: "updates", Value: bson.A{update}}}
+	}
+	limit := 0
+	if event.Operation == "deleteOne" {
+		limit = 1
+	}
+	return bson.D{{Key: "delete", Value: collection}, {Key: "deletes", Value: bson.A{bson.D{{Key: "q", Value: filter}, {Key: "limit", Value: limit}}}}}
+}
+
+// explainResultOf reads the result of explain. An aggregation that is not pushed down to the query layer
+// has the plan in the $cursor stage.
+func explainResultOf(explain bson.Raw) *ExplainResult {
+	root := explain
+	if _, err := root.LookupErr("queryPlanner"); err != nil {
+		if cursor, err := explain.LookupErr("stages", "0", "$cursor"); err == nil {
+			root, _ = cursor.DocumentOK()
+		}
+	}
+
+	result := &ExplainResult{}
+	if plan, ok := root.Lookup("queryPlanner", "winningPlan").DocumentOK(); ok {
+		// the slot based engine nests the plan in queryPlan
+		if queryPlan, ok := plan.Lookup("queryPlan").DocumentOK(); ok {
+			plan = queryPlan
+		}
+		result.WinningPlan = plan
+		result.Stage, result.IndexName = scanStageOf(plan)
+	}
+	if stats, ok := root.Lookup("executionStats").DocumentOK(); ok {
+		result.KeysExamined, _ = stats.Lookup("totalKeysExamined").AsInt64OK()
+		result.DocsExamined, _ = stats.Lookup("totalDocsExamined").AsInt64OK()
+		result.Returned, _ = stats.Lookup("nReturned").AsInt64OK()
+		millis, _ := stats.Lookup("executionTimeMillis").AsInt64OK()
+		result.ExecutionTime = time.Duration(millis) * time.Millisecond
+	}
+	return result
+}
+
+// scanStageOf returns the innermost stage of the plan, and the index of the first index scan on the way.
+func scanStageOf(plan bson.Raw) (string, string) {
+	stage, _ := plan.Lookup("stage").StringValueOK()
+	indexName, _ := plan.Lookup("indexName").StringValueOK()
+	input, ok := plan.Lookup("inputStage").DocumentOK()
+	if !ok {
+		input, ok = plan.Lookup("inputStages", "0").DocumentOK()
+	}
+	if !ok {
+		return stage, indexName
+	}
+	innerStage, innerIndex := scanStageOf(input)
+	if indexName == "" {
+		indexName = innerIndex
+	}
+	return innerStage, indexName
+}
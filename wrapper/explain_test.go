@@ -0,0 +1,134 @@
+package wrapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// slowLogger reports every query as slow, and passes the events to a channel.
+type slowLogger struct {
+	events chan SlowQueryEvent
+}
+
+func (l *slowLogger) SlowQuery(msg string)                             {}
+func (l *slowLogger) SlowQueryEvent(event SlowQueryEvent)              { l.events <- event }
+func (l *slowLogger) GetTimeoutDuration() time.Duration                { return 10 * time.Second }
+func (l *slowLogger) GetSlowQueryDurationOfOne() time.Duration         { return 0 }
+func (l *slowLogger) GetSlowQueryDurationOfMany() time.Duration        { return 0 }
+func (l *slowLogger) GetSlowQueryDurationOfBulk() time.Duration        { return 0 }
+func (l *slowLogger) GetSlowQueryDurationOfAggregation() time.Duration { return 0 }
+
+func rawOf(t *testing.T, doc bson.D) bson.Raw {
+	raw, err := bson.Marshal(doc)
+	assert.NoError(t, err)
+	return raw
+}
+
+func Test_explainResultOf(t *testing.T) {
+	stats := bson.E{Key: "executionStats", Value: bson.D{
+		{Key: "nReturned", Value: int32(1)},
+		{Key: "executionTimeMillis", Value: int32(3)},
+		{Key: "totalKeysExamined", Value: int32(1)},
+		{Key: "totalDocsExamined", Value: int64(1)},
+	}}
+	ixscan := bson.D{{Key: "stage", Value: "FETCH"}, {Key: "inputStage", Value: bson.D{{Key: "stage", Value: "IXSCAN"}, {Key: "indexName", Value: "account_id_1"}}}}
+
+	tests := []struct {
+		name    string
+		explain bson.D
+		want    ExplainResult
+	}{
+		{
+			name:    "index scan",
+			explain: bson.D{{Key: "queryPlanner", Value: bson.D{{Key: "winningPlan", Value: ixscan}}}, stats},
+			want:    ExplainResult{Stage: "IXSCAN", IndexName: "account_id_1", KeysExamined: 1, DocsExamined: 1, Returned: 1, ExecutionTime: 3 * time.Millisecond},
+		},
+		{
+			name:    "slot based engine",
+			explain: bson.D{{Key: "queryPlanner", Value: bson.D{{Key: "winningPlan", Value: bson.D{{Key: "queryPlan", Value: bson.D{{Key: "stage", Value: "COLLSCAN"}}}}}}}},
+			want:    ExplainResult{Stage: "COLLSCAN"},
+		},
+		{
+			name: "aggregation cursor stage",
+			explain: bson.D{{Key: "stages", Value: bson.A{
+				bson.D{{Key: "$cursor", Value: bson.D{{Key: "queryPlanner", Value: bson.D{{Key: "winningPlan", Value: ixscan}}}, stats}}},
+				bson.D{{Key: "$group", Value: bson.D{}}},
+			}}},
+			want: ExplainResult{Stage: "IXSCAN", IndexName: "account_id_1", KeysExamined: 1, DocsExamined: 1, Returned: 1, ExecutionTime: 3 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := explainResultOf(rawOf(t, tt.explain))
+			result.WinningPlan = nil
+			assert.Equal(t, &tt.want, result)
+		})
+	}
+}
+
+func Test_explainCommandOf(t *testing.T) {
+	filter := bson.M{"account_id": 1}
+	command := explainCommandOf("account", SlowQueryEvent{Operation: "findOne", Filter: filter, sort: bson.M{"limit": -1}})
+	assert.Equal(t, bson.D{{Key: "find", Value: "account"}, {Key: "filter", Value: filter}, {Key: "sort", Value: bson.M{"limit": -1}}, {Key: "limit", Value: 1}}, command)
+
+	command = explainCommandOf("account", SlowQueryEvent{Operation: "deleteMany", Filter: filter})
+	assert.Equal(t, bson.D{{Key: "delete", Value: "account"}, {Key: "deletes", Value: bson.A{bson.D{{Key: "q", Value: filter}, {Key: "limit", Value: 0}}}}}, command)
+}
+
+func Test_explainer(t *testing.T) {
+	e := &explainer{sampleRate: 1, limit: 2}
+	assert.True(t, e.allow())
+	assert.True(t, e.allow())
+	assert.False(t, e.allow())
+
+	e.windowStart = e.windowStart.Add(-time.Minute)
+	assert.True(t, e.allow())
+
+	assert.False(t, (&explainer{sampleRate: 0, limit: 2}).allow())
+}
+
+func Test_ExplainSlowQueries(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("attaches the plan", func(t *mtest.T) {
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetExplainSlowQueries(true))
+		t.AddMockResponses(
+			mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch, bson.D{{Key: "account_id", Value: 1}}),
+			mtest.CreateSuccessResponse(
+				bson.E{Key: "queryPlanner", Value: bson.D{{Key: "winningPlan", Value: bson.D{{Key: "stage", Value: "COLLSCAN"}}}}},
+				bson.E{Key: "executionStats", Value: bson.D{{Key: "nReturned", Value: 1}, {Key: "totalDocsExamined", Value: 100}}},
+			),
+		)
+
+		_, err := col.FindAll(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+		event := <-logger.events
+		assert.Equal(t, "findAll", event.Operation)
+		assert.Equal(t, "COLLSCAN", event.Explain.Stage)
+		assert.Equal(t, int64(100), event.Explain.DocsExamined)
+		assert.Contains(t, event.String(), "plan: COLLSCAN, keys examined: 0, docs examined: 100, returned: 1")
+
+		t.GetStartedEvent()
+		explain := t.GetStartedEvent().Command
+		assert.Equal(t, "executionStats", explain.Lookup("verbosity").StringValue())
+		assert.Equal(t, t.Coll.Name(), explain.Lookup("explain", "find").StringValue())
+	})
+
+	mt.Run("not explained", func(t *mtest.T) {
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		_, err := col.InsertOne(logger, account{AccountId: 1})
+		assert.NoError(t, err)
+		event := <-logger.events
+		assert.Nil(t, event.Explain)
+		assert.Equal(t, t.Coll.Name()+" insertOne slow query("+event.Elapsed.String()+") detected. document: {AccountId:1 Limit:0 Products:[]}", event.String())
+	})
+}
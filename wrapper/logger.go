@@ -1,6 +1,9 @@
 package wrapper
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Logger interface {
 	SlowQuery(msg string)
@@ -11,3 +14,25 @@ type Logger interface {
 	GetSlowQueryDurationOfBulk() time.Duration
 	GetSlowQueryDurationOfAggregation() time.Duration
 }
+
+// ContextLogger is a Logger that carries a request scoped context.
+// Wrapped functions derive their timeout context from it instead of context.Background().
+type ContextLogger interface {
+	Logger
+	Context() context.Context
+}
+
+// SlowQueryEventLogger is a Logger that takes the details of slow queries.
+// Slow queries are passed to SlowQueryEvent instead of SlowQuery.
+type SlowQueryEventLogger interface {
+	Logger
+	SlowQueryEvent(event SlowQueryEvent)
+}
+
+func newContext(logger Logger) (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if ctxLogger, ok := logger.(ContextLogger); ok && ctxLogger.Context() != nil {
+		ctx = ctxLogger.Context()
+	}
+	return context.WithTimeout(ctx, logger.GetTimeoutDuration())
+}
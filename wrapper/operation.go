@@ -2,153 +2,302 @@ package wrapper
 
 import (
 	"context"
-	"fmt"
-	"time"
 
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (col *Collection[T]) findOne(logger Logger, ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
-	startTime := time.Now()
+	filter = col.scopeFilter(filter)
+	ctx, startTime := col.startQuery(ctx)
 	singleResult := col.Collection.FindOne(ctx, filter, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s findOne slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
-	}
+	opt := options.MergeFindOneOptions(opts...)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "findOne", Filter: filter, sort: opt.Sort, hint: opt.Hint}, singleResult.Err())
 	return singleResult
 }
 
-func (col *Collection[T]) findAll(logger Logger, ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
-	startTime := time.Now()
+func (col *Collection[T]) findAll(logger Logger, ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*queryCursor, error) {
+	filter = col.scopeFilter(filter)
+	ctx, startTime := col.startQuery(ctx)
 	cursor, err := col.Collection.Find(ctx, filter, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfMany() {
-		logger.SlowQuery(fmt.Sprintf("%s findAll slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
-	}
-	return cursor, err
+	opt := options.MergeFindOptions(opts...)
+	return col.observeCursor(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "findAll", Filter: filter, sort: opt.Sort, hint: opt.Hint}, cursor, err)
 }
 
-func (col *Collection[T]) findOneAndModify(logger Logger, ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
-	startTime := time.Now()
+func (col *Collection[T]) findOneAndModify(logger Logger, ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (*mongo.SingleResult, error) {
+	source := update
+	update = col.stampUpdate(update, boolValue(options.MergeFindOneAndUpdateOptions(opts...).Upsert))
+	update = col.incrementVersion(update)
+	filter = col.scopeFilter(filter)
+	ctx, startTime := col.startQuery(ctx)
 	singleResult := col.Collection.FindOneAndUpdate(ctx, filter, update, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s findOneAndModify slow query(%v) detected. filter: %+v, update: %+v", col.Name(), time.Since(startTime), filter, update))
-	}
-	return singleResult
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "findOneAndModify", Filter: filter, Update: update, source: source}, singleResult.Err())
+	returnsAfter := returnsDocumentAfter(options.MergeFindOneAndUpdateOptions(opts...).ReturnDocument)
+	return col.auditSingleResult(ctx, singleResult, AuditUpdate, filter, update, nil, returnsAfter)
 }
 
-func (col *Collection[T]) findOneAndReplace(logger Logger, ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult {
-	startTime := time.Now()
-	singleResult := col.Collection.FindOneAndReplace(ctx, filter, replacement, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s findOneAndReplace slow query(%v) detected. filter: %+v, replacement: %+v", col.Name(), time.Since(startTime), filter, replacement))
+func (col *Collection[T]) findOneAndReplace(logger Logger, ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) (*mongo.SingleResult, error) {
+	source := replacement
+	filter, replacement = col.lockReplacement(filter, replacement)
+	filter = col.scopeFilter(filter)
+	replacement = col.stampReplacement(replacement)
+	ctx, startTime := col.startQuery(ctx)
+	var singleResult *mongo.SingleResult
+	if pipeline := col.keepCreatedAt(replacement); pipeline != nil {
+		singleResult = col.Collection.FindOneAndUpdate(ctx, filter, pipeline, findOneAndReplaceToUpdateOptions(opts...))
+	} else {
+		singleResult = col.Collection.FindOneAndReplace(ctx, filter, replacement, opts...)
 	}
-	return singleResult
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "findOneAndReplace", Filter: filter, Doc: replacement, source: source}, singleResult.Err())
+	returnsAfter := returnsDocumentAfter(options.MergeFindOneAndReplaceOptions(opts...).ReturnDocument)
+	return col.auditSingleResult(ctx, singleResult, AuditReplace, filter, nil, replacement, returnsAfter)
 }
 
-func (col *Collection[T]) findOneAndDelete(logger Logger, ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
-	startTime := time.Now()
-	singleResult := col.Collection.FindOneAndDelete(ctx, filter, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s findOneAndDelete slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
+func (col *Collection[T]) findOneAndDelete(logger Logger, ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) (*mongo.SingleResult, error) {
+	if col.softDeleteField != "" {
+		return col.softFindOneAndDelete(logger, ctx, filter, opts...)
 	}
-	return singleResult
+	ctx, startTime := col.startQuery(ctx)
+	singleResult := col.Collection.FindOneAndDelete(ctx, filter, opts...)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "findOneAndDelete", Filter: filter}, singleResult.Err())
+	return col.auditSingleResult(ctx, singleResult, AuditDelete, filter, nil, nil, false)
 }
 
 func (col *Collection[T]) insertOne(logger Logger, ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
-	startTime := time.Now()
+	source := document
+	document = col.stampInsert(document)
+	ctx, startTime := col.startQuery(ctx)
 	insertOneResult, err := col.Collection.InsertOne(ctx, document, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s insertOne slow query(%v) detected. document: %+v", col.Name(), time.Since(startTime), document))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "insertOne", Doc: document, source: source}, err)
+	if err != nil {
+		return insertOneResult, err
 	}
-	return insertOneResult, err
+	return insertOneResult, col.audit(ctx, col.auditEntry(AuditInsert, nil, nil, nil, toRaw(document), insertOneResult.InsertedID))
 }
 
 func (col *Collection[T]) insertMany(logger Logger, ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
-	startTime := time.Now()
+	source := documents
+	documents = col.stampInsertMany(documents)
+	ctx, startTime := col.startQuery(ctx)
 	insertOneResult, err := col.Collection.InsertMany(ctx, documents, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfMany() {
-		logger.SlowQuery(fmt.Sprintf("%s insertMany slow query(%v) detected. documents: %+v", col.Name(), time.Since(startTime), documents))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "insertMany", Doc: documents, source: source}, err)
+	if insertOneResult == nil {
+		return insertOneResult, err
+	}
+	// the driver already drops the ids of the documents that were not inserted
+	documents = succeeded(documents, err, options.MergeInsertManyOptions(opts...).Ordered)
+	if auditErr := col.auditInsertMany(ctx, documents, insertOneResult.InsertedIDs); auditErr != nil && err == nil {
+		err = auditErr
 	}
 	return insertOneResult, err
 }
 
+// succeeded drops the items that were not written because of a partial failure of insertMany or bulkWrite.
+// An ordered write stops at the first failure.
+func succeeded[E any](items []E, err error, ordered *bool) []E {
+	if err == nil {
+		return items
+	}
+	var bulkException mongo.BulkWriteException
+	if !errors.As(err, &bulkException) {
+		return nil
+	}
+	failed := make(map[int]bool, len(bulkException.WriteErrors))
+	for _, writeErr := range bulkException.WriteErrors {
+		failed[writeErr.Index] = true
+	}
+	result := make([]E, 0, len(items))
+	for i, item := range items {
+		if failed[i] {
+			if ordered == nil || *ordered {
+				break
+			}
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
 func (col *Collection[T]) updateOne(logger Logger, ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	startTime := time.Now()
+	source := update
+	upsert := boolValue(options.MergeUpdateOptions(opts...).Upsert)
+	update = col.stampUpdate(update, upsert)
+	update = col.incrementVersion(update)
+	filter = col.scopeFilter(filter)
+	if col.auditEnabled() {
+		if update, upsertedId, ok := col.upsertIdOf(filter, update, upsert); ok {
+			return col.updateOneAndAudit(logger, ctx, filter, update, source, upsertedId, opts...)
+		}
+	}
+	ctx, startTime := col.startQuery(ctx)
 	updateResult, err := col.Collection.UpdateOne(ctx, filter, update, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s UpdateOne slow query(%v) detected. filter: %+v, update: %+v", col.Name(), time.Since(startTime), filter, update))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "updateOne", Filter: filter, Update: update, source: source}, err)
+	if err != nil {
+		return updateResult, err
 	}
-	return updateResult, err
+	// an upsert of an id that is not known before the write is audited with the id of its filter, if any
+	return updateResult, col.auditUpdate(ctx, AuditUpdate, filter, update, nil, updateResult, nonNil(col.documentIdOf(filter)))
+}
+
+// updateOneAndAudit updates a document with findAndModify, so that the audit entry has its id and image before the update.
+func (col *Collection[T]) updateOneAndAudit(logger Logger, ctx context.Context, filter, update, source, upsertedId interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, startTime := col.startQuery(ctx)
+	singleResult := col.Collection.FindOneAndUpdate(ctx, filter, update, updateToFindOneAndUpdateOptions(opts...))
+	updateResult, before, err := findAndModifyResult(singleResult, upsertedId)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "updateOne", Filter: filter, Update: update, source: source}, err)
+	if err != nil {
+		return nil, err
+	}
+	return updateResult, col.auditOne(ctx, AuditUpdate, filter, update, nil, updateResult, before)
 }
 
 func (col *Collection[T]) updateMany(logger Logger, ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	startTime := time.Now()
+	source := update
+	updateOpts := options.MergeUpdateOptions(opts...)
+	update = col.stampUpdate(update, boolValue(updateOpts.Upsert))
+	update = col.incrementVersion(update)
+	filter = col.scopeFilter(filter)
+	ids, err := col.matchedIds(logger, ctx, filter, updateOpts.Collation, updateOpts.Hint)
+	if err != nil {
+		return nil, err
+	}
+	ctx, startTime := col.startQuery(ctx)
 	updateResult, err := col.Collection.UpdateMany(ctx, filter, update, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfMany() {
-		logger.SlowQuery(fmt.Sprintf("%s updateMany slow query(%v) detected. filter: %+v, update: %+v", col.Name(), time.Since(startTime), filter, update))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "updateMany", Filter: filter, Update: update, source: source}, err)
+	if err != nil {
+		return updateResult, err
 	}
-	return updateResult, err
+	return updateResult, col.auditUpdate(ctx, AuditUpdate, filter, update, nil, updateResult, ids)
 }
 
 func (col *Collection[T]) replaceOne(logger Logger, ctx context.Context, filter interface{}, document interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
-	startTime := time.Now()
-	result, err := col.Collection.ReplaceOne(ctx, filter, document, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s replaceOne slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
+	filter, document = col.lockReplacement(filter, document)
+	filter = col.scopeFilter(filter)
+	document = col.stampReplacement(document)
+	if col.auditEnabled() {
+		if upsertedId, ok := col.replacementUpsertIdOf(filter, document, boolValue(options.MergeReplaceOptions(opts...).Upsert)); ok {
+			return col.replaceOneAndAudit(logger, ctx, filter, document, upsertedId, opts...)
+		}
+	}
+	ctx, startTime := col.startQuery(ctx)
+	var result *mongo.UpdateResult
+	var err error
+	if pipeline := col.keepCreatedAt(document); pipeline != nil {
+		result, err = col.Collection.UpdateOne(ctx, filter, pipeline, replaceToUpdateOptions(opts...))
+	} else {
+		result, err = col.Collection.ReplaceOne(ctx, filter, document, opts...)
 	}
-	return result, err
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "replaceOne", Filter: filter}, err)
+	if err != nil {
+		return result, err
+	}
+	// an upsert of an id that is not known before the write is audited with the id of its filter, if any
+	return result, col.auditUpdate(ctx, AuditReplace, filter, nil, document, result, nonNil(col.documentIdOf(filter)))
+}
+
+// replaceOneAndAudit replaces a document with findAndModify, so that the audit entry has its id and image before the replacement.
+func (col *Collection[T]) replaceOneAndAudit(logger Logger, ctx context.Context, filter, document, upsertedId interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	ctx, startTime := col.startQuery(ctx)
+	var singleResult *mongo.SingleResult
+	if pipeline := col.keepCreatedAt(document); pipeline != nil {
+		singleResult = col.Collection.FindOneAndUpdate(ctx, filter, pipeline, updateToFindOneAndUpdateOptions(replaceToUpdateOptions(opts...)))
+	} else {
+		singleResult = col.Collection.FindOneAndReplace(ctx, filter, document, replaceToFindOneAndReplaceOptions(opts...))
+	}
+	result, before, err := findAndModifyResult(singleResult, upsertedId)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "replaceOne", Filter: filter}, err)
+	if err != nil {
+		return nil, err
+	}
+	return result, col.auditOne(ctx, AuditReplace, filter, nil, document, result, before)
 }
 
 func (col *Collection[T]) deleteOne(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-	startTime := time.Now()
-	deleteResult, err := col.Collection.DeleteOne(ctx, filter, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfOne() {
-		logger.SlowQuery(fmt.Sprintf("%s deleteOne slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
+	if col.softDeleteField != "" {
+		return col.softDeleteOne(logger, ctx, filter, opts...)
 	}
+	if col.auditEnabled() {
+		return col.deleteOneAndAudit(logger, ctx, filter, opts...)
+	}
+	ctx, startTime := col.startQuery(ctx)
+	deleteResult, err := col.Collection.DeleteOne(ctx, filter, opts...)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "deleteOne", Filter: filter}, err)
 	return deleteResult, err
 }
 
+// deleteOneAndAudit deletes a document with findAndModify, so that the audit entry has its id and image before the delete.
+func (col *Collection[T]) deleteOneAndAudit(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, startTime := col.startQuery(ctx)
+	singleResult := col.Collection.FindOneAndDelete(ctx, filter, deleteToFindOneAndDeleteOptions(opts...))
+	result, before, err := findAndModifyResult(singleResult, nil)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "deleteOne", Filter: filter}, err)
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: result.MatchedCount}, col.auditOne(ctx, AuditDelete, filter, nil, nil, result, before)
+}
+
 func (col *Collection[T]) deleteMany(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-	startTime := time.Now()
+	if col.softDeleteField != "" {
+		return col.softDeleteMany(logger, ctx, filter, opts...)
+	}
+	return col.purgeMany(logger, ctx, filter, opts...)
+}
+
+func (col *Collection[T]) purgeMany(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	deleteOpts := options.MergeDeleteOptions(opts...)
+	ids, err := col.matchedIds(logger, ctx, filter, deleteOpts.Collation, deleteOpts.Hint)
+	if err != nil {
+		return nil, err
+	}
+	ctx, startTime := col.startQuery(ctx)
 	deleteResult, err := col.Collection.DeleteMany(ctx, filter, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfMany() {
-		logger.SlowQuery(fmt.Sprintf("%s deleteMany slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "deleteMany", Filter: filter}, err)
+	if err != nil {
+		return deleteResult, err
 	}
-	return deleteResult, err
+	return deleteResult, col.auditDelete(ctx, filter, deleteResult, ids)
 }
 
 func (col *Collection[T]) countDocuments(logger Logger, ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
-	startTime := time.Now()
+	filter = col.scopeFilter(filter)
+	ctx, startTime := col.startQuery(ctx)
 	count, err := col.Collection.CountDocuments(ctx, filter, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfMany() {
-		logger.SlowQuery(fmt.Sprintf("%s countDocuments slow query(%v) detected. filter: %+v", col.Name(), time.Since(startTime), filter))
-	}
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "countDocuments", Filter: filter}, err)
 	return count, err
 }
 
 func (col *Collection[T]) estimatedDocumentCount(logger Logger, ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
-	startTime := time.Now()
-	count, err := col.Collection.EstimatedDocumentCount(ctx, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfMany() {
-		logger.SlowQuery(fmt.Sprintf("%s estimatedDocumentCount slow query(%v) detected.", col.Name(), time.Since(startTime)))
+	if col.softDeleteField != "" {
+		return col.countDocuments(logger, ctx, bson.M{}, estimatedToCountOptions(opts...))
 	}
+	ctx, startTime := col.startQuery(ctx)
+	count, err := col.Collection.EstimatedDocumentCount(ctx, opts...)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "estimatedDocumentCount"}, err)
 	return count, err
 }
 
 func (col *Collection[T]) bulkWrite(logger Logger, ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
-	startTime := time.Now()
-	bulkWriteResult, err := col.Collection.BulkWrite(ctx, models, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfBulk() {
-		logger.SlowQuery(fmt.Sprintf("%s bulkWrite slow query(%v) detected. models: %+v", col.Name(), time.Since(startTime), models))
+	source := models
+	models = col.lockModels(col.stampModels(models))
+	ctx, startTime := col.startQuery(ctx)
+	bulkWriteResult, err := col.Collection.BulkWrite(ctx, col.keepCreatedAtOfModels(models), opts...)
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfBulk(), SlowQueryEvent{Operation: "bulkWrite", Doc: models, source: source}, err)
+	if auditErr := col.auditModels(ctx, succeeded(models, err, options.MergeBulkWriteOptions(opts...).Ordered)); auditErr != nil && err == nil {
+		err = auditErr
 	}
 	return bulkWriteResult, err
 }
 
-func (col *Collection[T]) aggregate(logger Logger, ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
-	startTime := time.Now()
-	cursor, err := col.Collection.Aggregate(ctx, pipeline, opts...)
-	if time.Since(startTime) >= logger.GetSlowQueryDurationOfAggregation() {
-		logger.SlowQuery(fmt.Sprintf("%s aggregate slow query(%v) detected. pipeline: %+v", col.Name(), time.Since(startTime), pipeline))
+func (col *Collection[T]) aggregate(logger Logger, ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*queryCursor, error) {
+	pipeline, err := col.scopePipeline(pipeline)
+	if err != nil {
+		return nil, err
 	}
-	return cursor, err
+	ctx, startTime := col.startQuery(ctx)
+	cursor, err := col.Collection.Aggregate(ctx, pipeline, opts...)
+	return col.observeCursor(logger, ctx, startTime, logger.GetSlowQueryDurationOfAggregation(), SlowQueryEvent{Operation: "aggregate", Filter: pipeline}, cursor, err)
 }
@@ -0,0 +1,78 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func Test_PartialWrite(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("insert many", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}))
+
+		documents := []interface{}{bson.M{"_id": 1}, bson.M{"_id": 2}, bson.M{"_id": 3}}
+		ids, err := col.InsertMany(logger, documents, options.InsertMany().SetOrdered(false))
+		assert.Equal(t, []interface{}{int32(1), int32(3)}, ids)
+
+		var bulkErr *errorType.BulkError
+		assert.True(t, errors.As(err, &bulkErr))
+		assert.Equal(t, []int{1}, bulkErr.FailedIndexes())
+		assert.True(t, errorType.IsDuplicatedKeyErr(bulkErr.Failed(1)))
+	})
+
+	mt.Run("bulk write", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 121, Message: "document failed validation"}))
+
+		models := []mongo.WriteModel{mongo.NewInsertOneModel().SetDocument(bson.M{"limit": -1}), mongo.NewInsertOneModel().SetDocument(bson.M{"limit": 1})}
+		result, err := col.BulkWrite(logger, models, options.BulkWrite().SetOrdered(false))
+		assert.NotNil(t, result)
+		assert.True(t, errorType.IsBulkErr(err))
+
+		var bulkErr *errorType.BulkError
+		assert.True(t, errors.As(err, &bulkErr))
+		assert.True(t, errorType.IsDocumentValidationErr(bulkErr.Failed(0)))
+	})
+}
+
+func Test_QueryErrorOperation(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("update one", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		_, err := col.UpdateOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 1}})
+		var queryErr errorType.QueryError
+		assert.True(t, errors.As(err, &queryErr))
+		assert.Equal(t, "UpdateOne", queryErr.Operation())
+		assert.Equal(t, t.Coll.Name(), queryErr.Collection())
+	})
+
+	mt.Run("insert one with trx", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+
+		sessCtx := mongo.NewSessionContext(context.Background(), nil)
+		_, err := col.InsertOneWithTrx(logger, bson.M{"_id": 1}, &sessCtx)
+		var queryErr errorType.QueryError
+		assert.True(t, errors.As(err, &queryErr))
+		assert.Equal(t, "InsertOne", queryErr.Operation())
+		assert.Equal(t, 11000, queryErr.Code())
+	})
+}
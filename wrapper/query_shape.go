@@ -0,0 +1,93 @@
+package wrapper
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const shapePlaceholder = "?"
+
+// QueryShape returns the shape of a filter or a pipeline: the fields and operators of the query with every value
+// replaced by "?". Queries that differ only in their values have the same shape, such as
+// {account_id: ?, limit: {$gt: ?}}.
+// Fields are sorted, except in $sort where the order and the directions are part of the shape, an array of values
+// is a single "?", and field paths such as "$amount" are kept. It returns an empty string for a nil query or
+// a query that does not marshal.
+func QueryShape(query interface{}) string {
+	if query == nil {
+		return ""
+	}
+	valueType, data, err := bson.MarshalValue(query)
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeShape(&sb, bson.RawValue{Type: valueType, Value: data}, false)
+	return sb.String()
+}
+
+// writeShape writes the shape of a value. A value of $sort is written as it is.
+func writeShape(sb *strings.Builder, value bson.RawValue, inSort bool) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		writeDocumentShape(sb, value.Document(), inSort)
+	case bsontype.Array:
+		values, _ := value.Array().Values()
+		if !hasDocument(values) {
+			sb.WriteString(shapePlaceholder)
+			return
+		}
+		sb.WriteString("[")
+		for i, v := range values {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeShape(sb, v, false)
+		}
+		sb.WriteString("]")
+	case bsontype.String:
+		if s := value.StringValue(); strings.HasPrefix(s, "$") {
+			sb.WriteString(`"` + s + `"`)
+			return
+		}
+		sb.WriteString(shapePlaceholder)
+	default:
+		if inSort {
+			if direction, ok := value.AsInt64OK(); ok {
+				sb.WriteString(strconv.FormatInt(direction, 10))
+				return
+			}
+		}
+		sb.WriteString(shapePlaceholder)
+	}
+}
+
+func writeDocumentShape(sb *strings.Builder, doc bson.Raw, inSort bool) {
+	elements, _ := doc.Elements()
+	if !inSort {
+		sort.SliceStable(elements, func(i, j int) bool { return elements[i].Key() < elements[j].Key() })
+	}
+	sb.WriteString("{")
+	for i, element := range elements {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(element.Key())
+		sb.WriteString(": ")
+		writeShape(sb, element.Value(), inSort || element.Key() == "$sort")
+	}
+	sb.WriteString("}")
+}
+
+func hasDocument(values []bson.RawValue) bool {
+	for _, v := range values {
+		if v.Type == bsontype.EmbeddedDocument || v.Type == bsontype.Array {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package wrapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_QueryShape(t *testing.T) {
+	tests := []struct {
+		name  string
+		query interface{}
+		want  string
+	}{
+		{
+			name:  "values are placeholders",
+			query: bson.M{"limit": bson.M{"$gt": 100}, "account_id": 1},
+			want:  "{account_id: ?, limit: {$gt: ?}}",
+		},
+		{
+			name:  "array of values",
+			query: bson.D{{Key: "account_id", Value: bson.M{"$in": bson.A{1, 2, 3}}}},
+			want:  "{account_id: {$in: ?}}",
+		},
+		{
+			name:  "logical operators",
+			query: bson.M{"$or": bson.A{bson.M{"account_id": 1}, bson.M{"products": "Brokerage"}}},
+			want:  "{$or: [{account_id: ?}, {products: ?}]}",
+		},
+		{
+			name: "pipeline",
+			query: bson.A{
+				bson.M{"$match": bson.M{"account_id": 1}},
+				bson.M{"$sort": bson.D{{Key: "limit", Value: -1}, {Key: "account_id", Value: 1}}},
+				bson.M{"$group": bson.M{"_id": "$account_id", "total": bson.M{"$sum": "$limit"}}},
+				bson.M{"$limit": 10},
+			},
+			want: `[{$match: {account_id: ?}}, {$sort: {limit: -1, account_id: 1}}, {$group: {_id: "$account_id", total: {$sum: "$limit"}}}, {$limit: ?}]`,
+		},
+		{
+			name:  "nil",
+			query: nil,
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, QueryShape(tt.query))
+		})
+	}
+
+	assert.Equal(t, QueryShape(bson.M{"account_id": 1, "limit": 2}), QueryShape(bson.D{{Key: "limit", Value: 5}, {Key: "account_id", Value: 7}}))
+}
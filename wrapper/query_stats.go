@@ -0,0 +1,183 @@
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// queryStatsSamples is the number of the latest durations kept for the percentiles of a query shape.
+const queryStatsSamples = 1024
+
+// QueryStat is the statistics of the queries of a shape run by an operation on a collection.
+type QueryStat struct {
+	Collection string
+	Operation  string
+	// Shape is the QueryShape of the filter, or of the pipeline of an aggregation.
+	Shape string
+	Count int64
+	// Errors is the number of queries that failed. A query finding no document is not a failure.
+	Errors int64
+	// Total, Max and the percentiles are of the times that decide whether a query is slow, which are the server time
+	// of its commands if the collection measures commands.
+	Total time.Duration
+	Max   time.Duration
+	// P50, P95 and P99 are the percentiles of the latest 1024 queries.
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+func (s QueryStat) String() string {
+	return fmt.Sprintf("%s %s %s count: %d, errors: %d, p50: %v, p95: %v, p99: %v, max: %v",
+		s.Collection, s.Operation, s.Shape, s.Count, s.Errors, s.P50, s.P95, s.P99, s.Max)
+}
+
+// QueryStats keeps the statistics of queries by collection, operation and query shape.
+// Collections share a QueryStats through CollectionOptions.SetQueryStats. It is safe for concurrent use.
+type QueryStats struct {
+	mu      sync.Mutex
+	entries map[queryKey]*queryEntry
+}
+
+type queryKey struct {
+	collection string
+	operation  string
+	shape      string
+}
+
+type queryEntry struct {
+	count   int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+	next    int
+}
+
+func NewQueryStats() *QueryStats {
+	return &QueryStats{entries: make(map[queryKey]*queryEntry)}
+}
+
+// record adds a query that took elapsed, the same time that decides whether the query is slow.
+func (s *QueryStats) record(event SlowQueryEvent, elapsed time.Duration, err error) {
+	key := queryKey{collection: event.Collection, operation: event.Operation, shape: event.Shape}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &queryEntry{}
+		s.entries[key] = entry
+	}
+	entry.count++
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		entry.errors++
+	}
+	entry.total += elapsed
+	if elapsed > entry.max {
+		entry.max = elapsed
+	}
+	if len(entry.samples) < queryStatsSamples {
+		entry.samples = append(entry.samples, elapsed)
+		return
+	}
+	entry.samples[entry.next] = elapsed
+	entry.next = (entry.next + 1) % queryStatsSamples
+}
+
+// Snapshot returns the statistics recorded since the last report or reset, the most total time first.
+func (s *QueryStats) Snapshot() []QueryStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// Top returns the first n statistics of Snapshot.
+func (s *QueryStats) Top(n int) []QueryStat {
+	return top(s.Snapshot(), n)
+}
+
+// Reset drops the recorded statistics.
+func (s *QueryStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[queryKey]*queryEntry)
+}
+
+// Report passes the top n statistics of every interval to report, and starts the next interval from zero.
+// An interval without queries is not reported. It blocks until ctx is done.
+func (s *QueryStats) Report(ctx context.Context, interval time.Duration, n int, report func(top []QueryStat)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if stats := top(s.drain(), n); len(stats) > 0 {
+				report(stats)
+			}
+		}
+	}
+}
+
+// drain returns the snapshot and resets the statistics.
+func (s *QueryStats) drain() []QueryStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.snapshot()
+	s.entries = make(map[queryKey]*queryEntry)
+	return stats
+}
+
+func (s *QueryStats) snapshot() []QueryStat {
+	stats := make([]QueryStat, 0, len(s.entries))
+	for key, entry := range s.entries {
+		samples := append([]time.Duration(nil), entry.samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		stats = append(stats, QueryStat{
+			Collection: key.collection,
+			Operation:  key.operation,
+			Shape:      key.shape,
+			Count:      entry.count,
+			Errors:     entry.errors,
+			Total:      entry.total,
+			Max:        entry.max,
+			P50:        percentile(samples, 0.5),
+			P95:        percentile(samples, 0.95),
+			P99:        percentile(samples, 0.99),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].String() < stats[j].String()
+	})
+	return stats
+}
+
+// percentile returns the nearest rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func top(stats []QueryStat, n int) []QueryStat {
+	if n >= 0 && len(stats) > n {
+		return stats[:n]
+	}
+	return stats
+}
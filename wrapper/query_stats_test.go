@@ -0,0 +1,106 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_QueryStats(t *testing.T) {
+	stats := NewQueryStats()
+	for i := 1; i <= 100; i++ {
+		stats.record(SlowQueryEvent{Collection: "account", Operation: "findAll", Shape: "{account_id: ?}"}, time.Duration(i)*time.Millisecond, nil)
+	}
+	stats.record(SlowQueryEvent{Collection: "account", Operation: "findOne", Shape: "{account_id: ?}"}, time.Millisecond, mongo.ErrNoDocuments)
+	stats.record(SlowQueryEvent{Collection: "account", Operation: "findOne", Shape: "{account_id: ?}"}, 2*time.Millisecond, errors.New("connection reset by peer"))
+
+	snapshot := stats.Snapshot()
+	assert.Equal(t, []QueryStat{
+		{
+			Collection: "account", Operation: "findAll", Shape: "{account_id: ?}", Count: 100, Total: 5050 * time.Millisecond, Max: 100 * time.Millisecond,
+			P50: 50 * time.Millisecond, P95: 95 * time.Millisecond, P99: 99 * time.Millisecond,
+		},
+		{
+			Collection: "account", Operation: "findOne", Shape: "{account_id: ?}", Count: 2, Errors: 1, Total: 3 * time.Millisecond, Max: 2 * time.Millisecond,
+			P50: time.Millisecond, P95: 2 * time.Millisecond, P99: 2 * time.Millisecond,
+		},
+	}, snapshot)
+	assert.Equal(t, "account findOne {account_id: ?} count: 2, errors: 1, p50: 1ms, p95: 2ms, p99: 2ms, max: 2ms", snapshot[1].String())
+	assert.Equal(t, snapshot[:1], stats.Top(1))
+
+	stats.Reset()
+	assert.Empty(t, stats.Snapshot())
+}
+
+func Test_QueryStats_samples(t *testing.T) {
+	stats := NewQueryStats()
+	for i := 0; i < queryStatsSamples; i++ {
+		stats.record(SlowQueryEvent{Operation: "findAll"}, time.Second, nil)
+	}
+	for i := 0; i < queryStatsSamples; i++ {
+		stats.record(SlowQueryEvent{Operation: "findAll"}, time.Millisecond, nil)
+	}
+	stat := stats.Snapshot()[0]
+	assert.Equal(t, int64(2*queryStatsSamples), stat.Count)
+	assert.Equal(t, time.Second, stat.Max)
+	assert.Equal(t, time.Millisecond, stat.P99)
+}
+
+func Test_QueryStats_Report(t *testing.T) {
+	stats := NewQueryStats()
+	stats.record(SlowQueryEvent{Operation: "findAll"}, time.Second, nil)
+	stats.record(SlowQueryEvent{Operation: "findOne"}, time.Millisecond, nil)
+	reports := make(chan []QueryStat, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stats.Report(ctx, 10*time.Millisecond, 1, func(top []QueryStat) { reports <- top })
+		close(done)
+	}()
+
+	report := <-reports
+	assert.Len(t, report, 1)
+	assert.Equal(t, "findAll", report[0].Operation)
+	assert.Empty(t, stats.Snapshot())
+
+	cancel()
+	<-done
+}
+
+func Test_SetQueryStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("records every query by shape", func(t *mtest.T) {
+		stats := NewQueryStats()
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetQueryStats(stats))
+		logger := &myLogger{logrus.New()}
+		t.AddMockResponses(
+			mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}),
+		)
+
+		_, err := col.FindAll(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+		_, err = col.FindAll(logger, bson.M{"account_id": 2})
+		assert.NoError(t, err)
+		_, err = col.FindAll(logger, bson.M{"account_id": 3})
+		assert.Error(t, err)
+
+		snapshot := stats.Snapshot()
+		assert.Len(t, snapshot, 1)
+		assert.Equal(t, t.Coll.Name(), snapshot[0].Collection)
+		assert.Equal(t, "findAll", snapshot[0].Operation)
+		assert.Equal(t, "{account_id: ?}", snapshot[0].Shape)
+		assert.Equal(t, int64(3), snapshot[0].Count)
+		assert.Equal(t, int64(1), snapshot[0].Errors)
+	})
+}
@@ -0,0 +1,61 @@
+package wrapper
+
+import (
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//go:generate mockery --name Repository --output ./wrappertest --outpkg wrappertest --structname MockRepository --filename mock_repository.go
+//go:generate mockery --name SoftDeleteRepository --output ./wrappertest --outpkg wrappertest --structname MockSoftDeleteRepository --filename mock_soft_delete_repository.go
+//go:generate mockery --name AuditRepository --output ./wrappertest --outpkg wrappertest --structname MockAuditRepository --filename mock_audit_repository.go
+
+// Repository is the set of queries of Collection, so that fakes, mocks and decorators such as caches or metrics
+// can be used in place of a collection.
+// The WithTrx functions are left out, since InSession binds the queries to a session instead. The functions of
+// soft delete and audit are in SoftDeleteRepository and AuditRepository. WithDeleted, OnlyDeleted and NewBulkWriter
+// are left out, since they return the collection itself or a writer bound to it; call them on the Collection.
+type Repository[T any] interface {
+	Name() string
+	InSession(sessCtx mongo.SessionContext) Repository[T]
+
+	FindAll(logger Logger, filter interface{}, opts ...*options.FindOptions) ([]T, error)
+	FindOne(logger Logger, data, filter interface{}, opts ...*options.FindOneOptions) error
+	FindOneAndModify(logger Logger, data, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) error
+	FindOneAndReplace(logger Logger, data, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) error
+	FindOneAndDelete(logger Logger, data, filter interface{}, opts ...*options.FindOneAndDeleteOptions) error
+
+	InsertOne(logger Logger, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error)
+	InsertMany(logger Logger, documents []interface{}, opts ...*options.InsertManyOptions) (interface{}, error)
+	UpdateOne(logger Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(logger Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	ReplaceOne(logger Logger, filter interface{}, document interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
+	UpsertOne(logger Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*UpsertResult, error)
+	UpsertByKey(logger Logger, document T, keyFields ...string) (*UpsertResult, error)
+	DeleteOne(logger Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	DeleteMany(logger Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	BulkWrite(logger Logger, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+
+	CountDocuments(logger Logger, filter interface{}, opts ...*options.CountOptions) (int, error)
+	EstimatedDocumentCount(logger Logger, opts ...*options.EstimatedDocumentCountOptions) (int, error)
+	Aggregate(logger Logger, pipeline interface{}, opts ...*options.AggregateOptions) ([]T, error)
+}
+
+// SoftDeleteRepository is a Repository of a collection with soft delete.
+type SoftDeleteRepository[T any] interface {
+	Repository[T]
+
+	Restore(logger Logger, filter interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	Purge(logger Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+}
+
+// AuditRepository is a Repository of a collection with audit.
+type AuditRepository[T any] interface {
+	Repository[T]
+
+	AuditHistory(logger Logger, documentId interface{}, opts ...*options.FindOptions) ([]AuditEntry, error)
+}
+
+var (
+	_ SoftDeleteRepository[struct{}] = (*Collection[struct{}])(nil)
+	_ AuditRepository[struct{}]      = (*Collection[struct{}])(nil)
+)
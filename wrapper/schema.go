@@ -0,0 +1,272 @@
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ValidationLevel string
+
+const (
+	ValidationLevelOff      ValidationLevel = "off"
+	ValidationLevelStrict   ValidationLevel = "strict"
+	ValidationLevelModerate ValidationLevel = "moderate"
+)
+
+type ValidationAction string
+
+const (
+	ValidationActionError ValidationAction = "error"
+	ValidationActionWarn  ValidationAction = "warn"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	dateTimeType   = reflect.TypeOf(primitive.DateTime(0))
+	objectIDType   = reflect.TypeOf(primitive.ObjectID{})
+	decimalType    = reflect.TypeOf(primitive.Decimal128{})
+	binaryType     = reflect.TypeOf(primitive.Binary{})
+	documentType   = reflect.TypeOf(primitive.D{})
+	rawType        = reflect.TypeOf(bson.Raw{})
+	rawValueType   = reflect.TypeOf(bson.RawValue{})
+	byteSliceType  = reflect.TypeOf([]byte{})
+	emptyInterface = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// JSONSchema derives a $jsonSchema document from the bson layout of T.
+// A field is required unless it is a pointer or tagged omitempty, and `validate:"required"` always makes it required.
+// `validate:"oneof=a b c"` restricts the field to the listed values.
+func JSONSchema[T any]() (bson.M, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("json schema: %s is not a struct", t)
+	}
+	return structSchema(t, map[reflect.Type]bool{})
+}
+
+// ApplyValidator installs the schema of T as the collection validator via collMod.
+func (col *Collection[T]) ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error {
+	schema, err := JSONSchema[T]()
+	if err != nil {
+		return err
+	}
+	command := bson.D{
+		{Key: "collMod", Value: col.Name()},
+		{Key: "validator", Value: bson.M{"$jsonSchema": schema}},
+		{Key: "validationLevel", Value: level},
+		{Key: "validationAction", Value: action},
+	}
+	if err := col.Database().RunCommand(ctx, command).Err(); err != nil {
+		return col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), nil, command, nil), "ApplyValidator")
+	}
+	return nil
+}
+
+func structSchema(t reflect.Type, visiting map[reflect.Type]bool) (bson.M, error) {
+	schema := bson.M{"bsonType": "object"}
+	if visiting[t] {
+		// recursive types are only described down to the first repetition
+		return schema, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := bson.M{}
+	required := bson.A{}
+	if err := appendStructProperties(t, visiting, properties, &required); err != nil {
+		return nil, err
+	}
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+func appendStructProperties(t reflect.Type, visiting map[reflect.Type]bool, properties bson.M, required *bson.A) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tags, err := bsoncodec.DefaultStructTagParser(field)
+		if err != nil {
+			return err
+		}
+		if tags.Skip {
+			continue
+		}
+		if tags.Inline {
+			inlineType := field.Type
+			for inlineType.Kind() == reflect.Pointer {
+				inlineType = inlineType.Elem()
+			}
+			if inlineType.Kind() == reflect.Struct {
+				if err := appendStructProperties(inlineType, visiting, properties, required); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		rules := parseValidateTag(field.Tag.Get("validate"))
+		property, err := fieldSchema(field.Type, visiting)
+		if err != nil {
+			return err
+		}
+		if len(rules.oneOf) > 0 {
+			enum, err := enumValues(field.Type, rules.oneOf)
+			if err != nil {
+				return fmt.Errorf("json schema: field %s: %w", field.Name, err)
+			}
+			if field.Type.Kind() == reflect.Pointer {
+				enum = append(enum, nil)
+			}
+			property["enum"] = enum
+		}
+		properties[tags.Name] = property
+
+		if rules.required || (field.Type.Kind() != reflect.Pointer && !tags.OmitEmpty) {
+			*required = append(*required, tags.Name)
+		}
+	}
+	return nil
+}
+
+func fieldSchema(t reflect.Type, visiting map[reflect.Type]bool) (bson.M, error) {
+	if t.Kind() == reflect.Pointer {
+		schema, err := fieldSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(schema), nil
+	}
+
+	switch t {
+	case timeType, dateTimeType:
+		return bson.M{"bsonType": "date"}, nil
+	case objectIDType:
+		return bson.M{"bsonType": "objectId"}, nil
+	case decimalType:
+		return bson.M{"bsonType": "decimal"}, nil
+	case binaryType, byteSliceType:
+		return bson.M{"bsonType": "binData"}, nil
+	case documentType:
+		return bson.M{"bsonType": "object"}, nil
+	case rawType, rawValueType, emptyInterface:
+		return bson.M{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return bson.M{"bsonType": "string"}, nil
+	case reflect.Bool:
+		return bson.M{"bsonType": "bool"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return bson.M{"bsonType": bson.A{"int", "long"}}, nil
+	case reflect.Float32, reflect.Float64:
+		return bson.M{"bsonType": bson.A{"double", "int", "long"}}, nil
+	case reflect.Struct:
+		return structSchema(t, visiting)
+	case reflect.Slice, reflect.Array:
+		items, err := fieldSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema := bson.M{"bsonType": "array"}
+		if len(items) > 0 {
+			schema["items"] = items
+		}
+		if t.Kind() == reflect.Slice {
+			// nil slices are encoded as null
+			return nullable(schema), nil
+		}
+		return schema, nil
+	case reflect.Map:
+		values, err := fieldSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema := bson.M{"bsonType": "object"}
+		if len(values) > 0 {
+			schema["additionalProperties"] = values
+		}
+		// nil maps are encoded as null
+		return nullable(schema), nil
+	case reflect.Interface:
+		return bson.M{}, nil
+	}
+	return nil, fmt.Errorf("json schema: unsupported type %s", t)
+}
+
+func nullable(schema bson.M) bson.M {
+	switch bsonType := schema["bsonType"].(type) {
+	case string:
+		schema["bsonType"] = bson.A{bsonType, "null"}
+	case bson.A:
+		schema["bsonType"] = append(bsonType, "null")
+	}
+	return schema
+}
+
+type validateRules struct {
+	required bool
+	oneOf    []string
+}
+
+func parseValidateTag(tag string) validateRules {
+	var rules validateRules
+	for _, rule := range strings.Split(tag, ",") {
+		switch {
+		case rule == "required":
+			rules.required = true
+		case strings.HasPrefix(rule, "oneof="):
+			rules.oneOf = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+		}
+	}
+	return rules
+}
+
+func enumValues(t reflect.Type, values []string) (bson.A, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	enum := bson.A{}
+	for _, value := range values {
+		switch t.Kind() {
+		case reflect.String:
+			enum = append(enum, value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			enum = append(enum, n)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, err
+			}
+			enum = append(enum, f)
+		default:
+			return nil, fmt.Errorf("oneof is not supported on %s", t)
+		}
+	}
+	return enum, nil
+}
@@ -0,0 +1,95 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type schemaAddress struct {
+	City string `bson:"city"`
+	Zip  string `bson:"zip,omitempty"`
+}
+
+type SchemaBase struct {
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type schemaUser struct {
+	Id         primitive.ObjectID `bson:"_id"`
+	Name       string             `bson:"name"`
+	Nickname   *string            `bson:"nickname"`
+	Email      *string            `bson:"email" validate:"required"`
+	Status     string             `bson:"status" validate:"oneof=active blocked"`
+	Level      *int               `bson:"level" validate:"oneof=1 2"`
+	Score      float64            `bson:"score"`
+	Tags       []string           `bson:"tags"`
+	Address    schemaAddress      `bson:"address"`
+	Friends    []*schemaUser      `bson:"friends,omitempty"`
+	Ignored    string             `bson:"-"`
+	SchemaBase `bson:",inline"`
+}
+
+func Test_JSONSchema(t *testing.T) {
+	schema, err := JSONSchema[schemaUser]()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "object", schema["bsonType"])
+	assert.ElementsMatch(t, bson.A{"_id", "name", "email", "status", "score", "tags", "address", "created_at"}, schema["required"])
+
+	properties := schema["properties"].(bson.M)
+	assert.NotContains(t, properties, "Ignored")
+	assert.Equal(t, bson.M{"bsonType": "objectId"}, properties["_id"])
+	assert.Equal(t, bson.M{"bsonType": "string"}, properties["name"])
+	assert.Equal(t, bson.M{"bsonType": bson.A{"string", "null"}}, properties["nickname"])
+	assert.Equal(t, bson.M{"bsonType": "string", "enum": bson.A{"active", "blocked"}}, properties["status"])
+	assert.Equal(t, bson.M{"bsonType": bson.A{"int", "long", "null"}, "enum": bson.A{int64(1), int64(2), nil}}, properties["level"])
+	assert.Equal(t, bson.M{"bsonType": bson.A{"double", "int", "long"}}, properties["score"])
+	assert.Equal(t, bson.M{"bsonType": bson.A{"array", "null"}, "items": bson.M{"bsonType": "string"}}, properties["tags"])
+	assert.Equal(t, bson.M{"bsonType": "date"}, properties["created_at"])
+	assert.Equal(t, bson.M{
+		"bsonType":   "object",
+		"required":   bson.A{"city"},
+		"properties": bson.M{"city": bson.M{"bsonType": "string"}, "zip": bson.M{"bsonType": "string"}},
+	}, properties["address"])
+	assert.Equal(t, bson.M{
+		"bsonType": bson.A{"array", "null"},
+		"items":    bson.M{"bsonType": bson.A{"object", "null"}},
+	}, properties["friends"])
+}
+
+func Test_JSONSchema_NotStruct(t *testing.T) {
+	_, err := JSONSchema[[]string]()
+	assert.Error(t, err)
+}
+
+func Test_ApplyValidator(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(t *mtest.T) {
+		col := &Collection[schemaAddress]{Collection: t.Coll}
+		t.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := col.ApplyValidator(context.Background(), ValidationLevelModerate, ValidationActionError)
+		assert.NoError(t, err)
+
+		command := t.GetStartedEvent().Command
+		assert.Equal(t, t.Coll.Name(), command.Lookup("collMod").StringValue())
+		assert.Equal(t, "moderate", command.Lookup("validationLevel").StringValue())
+		assert.Equal(t, "object", command.Lookup("validator", "$jsonSchema", "bsonType").StringValue())
+	})
+
+	mt.Run("command failed", func(t *mtest.T) {
+		col := &Collection[schemaAddress]{Collection: t.Coll}
+		t.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Message: "unauthorized"}))
+
+		err := col.ApplyValidator(context.Background(), ValidationLevelStrict, ValidationActionError)
+		assert.Error(t, err)
+	})
+}
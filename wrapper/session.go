@@ -0,0 +1,31 @@
+package wrapper
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InSession returns a handle of the collection bound to sessCtx, as a Repository so that fakes and mocks can be bound too. Its functions run in the session,
+// and in the transaction of the session if one is running, without the WithTrx functions.
+// col itself is not bound, so use the returned handle. A collection that is not bound runs in the session of the
+// context of the logger instead, if the logger is a ContextLogger whose context is a session context.
+//
+//	err := mongoClient.Transaction(nil, nil, func(sessCtx mongo.SessionContext) (interface{}, error) {
+//		accounts, transactions := accountCol.InSession(sessCtx), transactionCol.InSession(sessCtx)
+//		...
+//	})
+func (col *Collection[T]) InSession(sessCtx mongo.SessionContext) Repository[T] {
+	bound := *col
+	bound.sessCtx = sessCtx
+	return &bound
+}
+
+// newContext is newContext of the logger derived from the session context if the collection is bound to one.
+// Otherwise the context of the logger carries its session, if any, to the driver.
+func (col *Collection[T]) newContext(logger Logger) (context.Context, context.CancelFunc) {
+	if col.sessCtx != nil {
+		return context.WithTimeout(col.sessCtx, logger.GetTimeoutDuration())
+	}
+	return newContext(logger)
+}
@@ -0,0 +1,74 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_InSession(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("functions run in the transaction", func(t *mtest.T) {
+		client := &Client{Client: t.Client}
+		accounts := NewCollection[account](client, t.DB.Name(), t.Coll.Name())
+		histories := NewCollection[account](client, t.DB.Name(), "histories")
+		t.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		_, err := Transaction(context.Background(), client, func(sessCtx mongo.SessionContext) (bool, error) {
+			if _, err := accounts.InSession(sessCtx).UpdateOne(logger, bson.M{"account_id": 1}, bson.M{"$inc": bson.M{"limit": -1}}); err != nil {
+				return false, err
+			}
+			_, err := histories.InSession(sessCtx).InsertOne(logger, bson.M{"account_id": 1})
+			return true, err
+		})
+		assert.NoError(t, err)
+
+		update := t.GetStartedEvent().Command
+		assert.Equal(t, t.Coll.Name(), update.Lookup("update").StringValue())
+		assert.True(t, update.Lookup("startTransaction").Boolean())
+		insert := t.GetStartedEvent().Command
+		assert.Equal(t, "histories", insert.Lookup("insert").StringValue())
+		assert.Equal(t, update.Lookup("lsid"), insert.Lookup("lsid"))
+		assert.Equal(t, update.Lookup("txnNumber"), insert.Lookup("txnNumber"))
+		assert.Equal(t, "commitTransaction", t.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("collection joins the session of the logger context", func(t *mtest.T) {
+		client := &Client{Client: t.Client}
+		col := NewCollection[account](client, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), mtest.CreateSuccessResponse())
+
+		_, err := Transaction(context.Background(), client, func(sessCtx mongo.SessionContext) (bool, error) {
+			_, err := col.InsertOne(&myContextLogger{logger, sessCtx}, bson.M{"account_id": 1})
+			return true, err
+		})
+		assert.NoError(t, err)
+		assert.True(t, t.GetStartedEvent().Command.Lookup("startTransaction").Boolean())
+		assert.Equal(t, "commitTransaction", t.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("InSession does not bind the collection itself", func(t *mtest.T) {
+		client := &Client{Client: t.Client}
+		col := NewCollection[account](client, t.DB.Name(), t.Coll.Name())
+
+		_, err := Transaction(context.Background(), client, func(sessCtx mongo.SessionContext) (bool, error) {
+			bound := col.InSession(sessCtx)
+			assert.Equal(t, sessCtx, bound.(*Collection[account]).sessCtx)
+			return true, nil
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, col.sessCtx)
+	})
+}
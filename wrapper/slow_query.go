@@ -0,0 +1,156 @@
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+)
+
+// Severity tells how slow a slow query is.
+type Severity int
+
+const (
+	// SeveritySlow is a query that took the slow query duration of the logger or longer.
+	SeveritySlow Severity = iota
+	// SeverityCritical is a query that took the critical factor of the collection times the slow query duration or longer.
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	if s == SeverityCritical {
+		return "critical"
+	}
+	return "slow"
+}
+
+// SlowQueryEvent describes a query that took longer than the slow query duration of the logger.
+type SlowQueryEvent struct {
+	Collection string
+	// Operation is the query run on the collection, such as "findAll" or "updateMany".
+	Operation string
+	// Elapsed is the total time of the query. It includes decoding the cursor if the collection measures commands.
+	Elapsed   time.Duration
+	Threshold time.Duration
+	Severity  Severity
+	// Timing is the time of the commands of the query if the collection measures commands.
+	// The query is slow if Timing.Server is the threshold or longer.
+	Timing *CommandTiming
+	// Filter is the filter of the query, or the pipeline of an aggregation.
+	Filter interface{}
+	Update interface{}
+	// Doc is the inserted documents, the replacement or the write models.
+	Doc interface{}
+	// Shape is the QueryShape of the filter, or of the pipeline of an aggregation.
+	Shape string
+	// Explain is the plan of the query if the collection explains slow queries.
+	Explain *ExplainResult
+	// Suppressed is the number of similar slow queries the slow query sampler of the collection did not report
+	// in SuppressedFor before this one.
+	Suppressed    int
+	SuppressedFor time.Duration
+
+	sort interface{}
+	hint interface{}
+	// source is the update, documents or models as they were passed. Stamping them for timestamps or versioning
+	// converts structs to documents, so the fields tagged `mongo:"sensitive"` are taken from source.
+	source interface{}
+	// documentType is the type of the documents of the collection. Its fields tagged `mongo:"sensitive"` are
+	// redacted in filters and updates that are not of the type, such as bson.M.
+	documentType reflect.Type
+}
+
+// String returns the message passed to Logger.SlowQuery.
+func (e SlowQueryEvent) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s %s query(%v) detected.", e.Collection, e.Operation, e.Severity, e.Elapsed)
+	var details []string
+	if e.Filter != nil {
+		label := "filter"
+		if e.Operation == "aggregate" {
+			label = "pipeline"
+		}
+		details = append(details, fmt.Sprintf("%s: %s", label, errorType.RedactAs(e.Filter, e.source, e.documentType)))
+	}
+	if e.Update != nil {
+		details = append(details, fmt.Sprintf("update: %s", errorType.RedactAs(e.Update, e.source, e.documentType)))
+	}
+	if e.Doc != nil {
+		details = append(details, fmt.Sprintf("%s: %s", docLabelOf(e.Operation), errorType.RedactAs(e.Doc, e.source, e.documentType)))
+	}
+	if e.Timing != nil {
+		details = append(details, e.Timing.String())
+	}
+	if e.Explain != nil {
+		details = append(details, e.Explain.String())
+	}
+	if e.Suppressed > 0 {
+		details = append(details, fmt.Sprintf("suppressed %d similar slow queries in %v", e.Suppressed, e.SuppressedFor.Round(time.Millisecond)))
+	}
+	if len(details) > 0 {
+		sb.WriteString(" ")
+		sb.WriteString(strings.Join(details, ", "))
+	}
+	return sb.String()
+}
+
+func docLabelOf(operation string) string {
+	switch operation {
+	case "insertOne":
+		return "document"
+	case "insertMany":
+		return "documents"
+	case "bulkWrite":
+		return "models"
+	}
+	return "replacement"
+}
+
+// observe records the query in the query stats of the collection, and reports it to the logger if it took
+// threshold or longer and the slow query sampler of the collection allows it. The time of a query is the time since
+// startTime, or the time of its commands in ctx if the collection measures commands.
+// The report waits for explain in the background if the collection explains slow queries.
+func (col *Collection[T]) observe(logger Logger, ctx context.Context, startTime time.Time, threshold time.Duration, event SlowQueryEvent, err error) {
+	elapsed := time.Since(startTime)
+	measured := elapsed
+	if trace := traceOf(ctx); col.measureCommands && trace != nil {
+		if event.Timing = trace.timingSince(startTime); event.Timing != nil {
+			measured = event.Timing.Server
+		}
+	}
+	slow := measured >= threshold
+	if !slow && col.queryStats == nil {
+		return
+	}
+	event.Collection, event.Elapsed, event.Threshold = col.Name(), elapsed, threshold
+	event.Shape = QueryShape(event.Filter)
+	event.documentType = documentTypeOf[T]()
+	if col.queryStats != nil {
+		col.queryStats.record(event, measured, err)
+	}
+	if !slow {
+		return
+	}
+	if col.criticalFactor > 0 && float64(measured) >= col.criticalFactor*float64(threshold) {
+		event.Severity = SeverityCritical
+	}
+	if col.sampler != nil && !col.sampler.allow(logger, &event) {
+		return
+	}
+	if col.explainer != nil && explainable(event.Operation) && col.explainer.allow() {
+		go col.explain(logger, event)
+		return
+	}
+	reportSlowQuery(logger, event)
+}
+
+func reportSlowQuery(logger Logger, event SlowQueryEvent) {
+	if eventLogger, ok := logger.(SlowQueryEventLogger); ok {
+		eventLogger.SlowQueryEvent(event)
+		return
+	}
+	logger.SlowQuery(event.String())
+}
@@ -0,0 +1,138 @@
+package wrapper
+
+import (
+	"sync"
+	"time"
+)
+
+// SlowQuerySampler limits the slow queries reported to the logger. Of the slow queries with the same collection,
+// operation and shape, it reports the first n of every interval and then one in every m.
+// A reported event tells how many similar slow queries were suppressed since the previous one. When an interval with
+// suppressed queries ends, the last suppressed query is reported with the rest, so that a burst is not left unreported.
+// Queries with SeverityCritical are always reported.
+// Collections share a SlowQuerySampler through CollectionOptions.SetSlowQuerySampler. It is safe for concurrent use.
+type SlowQuerySampler struct {
+	first    int
+	every    int
+	interval time.Duration
+
+	mu        sync.Mutex
+	entries   map[sampleKey]*sampleEntry
+	lastSweep time.Time
+}
+
+type sampleKey struct {
+	collection string
+	operation  string
+	shape      string
+}
+
+type sampleEntry struct {
+	windowStart time.Time
+	seen        int
+	suppressed  int
+	lastLogged  time.Time
+	// last is the last suppressed query, at lastAt, and the logger it was for, reported when the interval ends.
+	last   SlowQueryEvent
+	lastAt time.Time
+	logger Logger
+	timer  *time.Timer
+}
+
+// NewSlowQuerySampler returns a sampler that reports the first slow queries of every interval and then one in every.
+// An every of zero or less reports no more slow queries in the interval.
+func NewSlowQuerySampler(first, every int, interval time.Duration) *SlowQuerySampler {
+	return &SlowQuerySampler{first: first, every: every, interval: interval, entries: make(map[sampleKey]*sampleEntry)}
+}
+
+// allow tells whether the event is reported, and sets the suppressed queries of a reported event.
+// The summaries of the intervals that ended are reported to their loggers before it returns.
+func (s *SlowQuerySampler) allow(logger Logger, event *SlowQueryEvent) bool {
+	if event.Severity == SeverityCritical {
+		return true
+	}
+	key := sampleKey{collection: event.Collection, operation: event.Operation, shape: event.Shape}
+	now := time.Now()
+	s.mu.Lock()
+	summaries := s.sweep(now)
+	entry, ok := s.entries[key]
+	if ok && now.Sub(entry.windowStart) >= s.interval {
+		summaries = append(summaries, s.rollOver(key, entry))
+		ok = false
+	}
+	if !ok {
+		entry = &sampleEntry{windowStart: now, lastLogged: now}
+		s.entries[key] = entry
+	}
+	entry.seen++
+	allowed := entry.seen <= s.first || (s.every > 0 && (entry.seen-s.first)%s.every == 0)
+	if !allowed {
+		entry.suppressed++
+		entry.last, entry.lastAt, entry.logger = *event, now, logger
+		if entry.timer == nil {
+			entry.timer = time.AfterFunc(entry.windowStart.Add(s.interval).Sub(now), func() { s.end(key, entry) })
+		}
+	} else {
+		if entry.suppressed > 0 {
+			event.Suppressed, event.SuppressedFor = entry.suppressed, now.Sub(entry.lastLogged)
+		}
+		entry.suppressed, entry.lastLogged = 0, now
+	}
+	s.mu.Unlock()
+
+	reportSummaries(summaries)
+	return allowed
+}
+
+// end reports the summary of the entry when its interval ends, unless a query or a sweep has rolled it over already.
+func (s *SlowQuerySampler) end(key sampleKey, entry *sampleEntry) {
+	s.mu.Lock()
+	if s.entries[key] != entry {
+		s.mu.Unlock()
+		return
+	}
+	summary := s.rollOver(key, entry)
+	s.mu.Unlock()
+	reportSummaries([]*sampleEntry{summary})
+}
+
+// rollOver removes the entry of an interval that ended, and returns it if it has a summary to report.
+func (s *SlowQuerySampler) rollOver(key sampleKey, entry *sampleEntry) *sampleEntry {
+	delete(s.entries, key)
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	if entry.suppressed == 0 {
+		return nil
+	}
+	return entry
+}
+
+// sweep rolls over the entries of intervals that ended, at most once an interval, and returns their summaries.
+func (s *SlowQuerySampler) sweep(now time.Time) []*sampleEntry {
+	if now.Sub(s.lastSweep) < s.interval {
+		return nil
+	}
+	s.lastSweep = now
+	var summaries []*sampleEntry
+	for key, entry := range s.entries {
+		if now.Sub(entry.windowStart) >= s.interval {
+			summaries = append(summaries, s.rollOver(key, entry))
+		}
+	}
+	return summaries
+}
+
+// reportSummaries reports the last suppressed query of every entry with the other suppressed queries.
+func reportSummaries(summaries []*sampleEntry) {
+	for _, entry := range summaries {
+		if entry == nil {
+			continue
+		}
+		event := entry.last
+		if entry.suppressed > 1 {
+			event.Suppressed, event.SuppressedFor = entry.suppressed-1, entry.lastAt.Sub(entry.lastLogged)
+		}
+		reportSlowQuery(entry.logger, event)
+	}
+}
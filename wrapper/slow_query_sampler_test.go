@@ -0,0 +1,133 @@
+package wrapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_SlowQuerySampler(t *testing.T) {
+	logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+	sampler := NewSlowQuerySampler(2, 3, time.Hour)
+	var allowed []bool
+	var suppressed []int
+	for i := 0; i < 8; i++ {
+		event := SlowQueryEvent{Collection: "account", Operation: "findAll", Shape: "{account_id: ?}"}
+		allowed = append(allowed, sampler.allow(logger, &event))
+		suppressed = append(suppressed, event.Suppressed)
+	}
+	assert.Equal(t, []bool{true, true, false, false, true, false, false, true}, allowed)
+	assert.Equal(t, []int{0, 0, 0, 0, 2, 0, 0, 2}, suppressed)
+
+	for i := 0; i < 5; i++ {
+		critical := SlowQueryEvent{Collection: "account", Operation: "findAll", Shape: "{account_id: ?}", Severity: SeverityCritical}
+		assert.True(t, sampler.allow(logger, &critical), "a critical query is always reported")
+	}
+
+	for i := 0; i < 2; i++ {
+		event := SlowQueryEvent{Collection: "account", Operation: "findAll", Shape: "{account_id: ?}", Elapsed: time.Duration(i + 1)}
+		assert.False(t, sampler.allow(logger, &event))
+	}
+	sampler.entries[sampleKey{collection: "account", operation: "findAll", shape: "{account_id: ?}"}].windowStart = time.Now().Add(-time.Hour)
+	event := SlowQueryEvent{Collection: "account", Operation: "findAll", Shape: "{account_id: ?}"}
+	assert.True(t, sampler.allow(logger, &event), "the next interval reports the first queries again")
+	assert.Equal(t, 0, event.Suppressed)
+	summary := <-logger.events
+	assert.Equal(t, time.Duration(2), summary.Elapsed, "the last suppressed query is reported with the others")
+	assert.Equal(t, 1, summary.Suppressed)
+	assert.Greater(t, summary.SuppressedFor, time.Duration(0))
+
+	none := NewSlowQuerySampler(1, 0, time.Hour)
+	assert.True(t, none.allow(logger, &SlowQueryEvent{}))
+	for i := 0; i < 10; i++ {
+		assert.False(t, none.allow(logger, &SlowQueryEvent{}))
+	}
+}
+
+func Test_SlowQuerySampler_end(t *testing.T) {
+	logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+	sampler := NewSlowQuerySampler(1, 0, 50*time.Millisecond)
+	for i := 0; i < 4; i++ {
+		sampler.allow(logger, &SlowQueryEvent{Shape: "{a: ?}"})
+	}
+
+	select {
+	case summary := <-logger.events:
+		assert.Equal(t, 2, summary.Suppressed, "a burst that ended is reported without another query")
+	case <-time.After(time.Second):
+		t.Fatal("the summary of the interval is not reported")
+	}
+	sampler.mu.Lock()
+	assert.Empty(t, sampler.entries)
+	sampler.mu.Unlock()
+}
+
+func Test_SlowQuerySampler_sweep(t *testing.T) {
+	logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+	sampler := NewSlowQuerySampler(1, 0, time.Minute)
+	sampler.allow(logger, &SlowQueryEvent{Shape: "{a: ?}"})
+	sampler.allow(logger, &SlowQueryEvent{Shape: "{b: ?}"})
+	sampler.allow(logger, &SlowQueryEvent{Shape: "{b: ?}"})
+	sampler.allow(logger, &SlowQueryEvent{Shape: "{c: ?}"})
+	for key, entry := range sampler.entries {
+		if key.shape != "{c: ?}" {
+			entry.windowStart = entry.windowStart.Add(-time.Minute)
+		}
+	}
+
+	sampler.mu.Lock()
+	sampler.lastSweep = sampler.lastSweep.Add(-time.Minute)
+	summaries := sampler.sweep(time.Now())
+	sampler.mu.Unlock()
+	reportSummaries(summaries)
+	assert.Equal(t, "{b: ?}", (<-logger.events).Shape)
+	assert.Len(t, sampler.entries, 1)
+	assert.Contains(t, sampler.entries, sampleKey{shape: "{c: ?}"})
+}
+
+func Test_SlowQueryEvent_String(t *testing.T) {
+	event := SlowQueryEvent{
+		Collection: "account", Operation: "findAll", Elapsed: 3 * time.Second, Severity: SeverityCritical,
+		Filter: bson.M{"account_id": 1}, Suppressed: 523, SuppressedFor: 10 * time.Second,
+	}
+	assert.Equal(t, `account findAll critical query(3s) detected. filter: map[account_id:1], suppressed 523 similar slow queries in 10s`, event.String())
+}
+
+func Test_SetSlowQueryCriticalFactor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("escalates and is not sampled", func(t *mtest.T) {
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 3)}
+		opts := NewCollectionOptions().SetSlowQueryCriticalFactor(3).SetSlowQuerySampler(NewSlowQuerySampler(1, 0, time.Hour))
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), opts)
+		for i := 0; i < 3; i++ {
+			t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+			_, err := col.DeleteMany(logger, bson.M{"account_id": i})
+			assert.NoError(t, err)
+		}
+		close(logger.events)
+
+		var events []SlowQueryEvent
+		for event := range logger.events {
+			events = append(events, event)
+		}
+		assert.Len(t, events, 3)
+		for _, event := range events {
+			assert.Equal(t, SeverityCritical, event.Severity)
+			assert.Equal(t, 0, event.Suppressed)
+		}
+	})
+
+	mt.Run("not escalated", func(t *mtest.T) {
+		logger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		_, err := col.DeleteMany(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, SeveritySlow, (<-logger.events).Severity)
+	})
+}
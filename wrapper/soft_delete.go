@@ -0,0 +1,300 @@
+package wrapper
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+const defaultSoftDeleteField = "deletedAt"
+
+type deletedScope int
+
+const (
+	excludeDeleted deletedScope = iota
+	withDeleted
+	onlyDeleted
+)
+
+// stages that must stay at the beginning of a pipeline
+var leadingStages = map[string]bool{
+	"$geoNear":                 true,
+	"$search":                  true,
+	"$searchMeta":              true,
+	"$vectorSearch":            true,
+	"$collStats":               true,
+	"$indexStats":              true,
+	"$changeStream":            true,
+	"$listSessions":            true,
+	"$currentOp":               true,
+	"$documents":               true,
+	"$shardedDataDistribution": true,
+}
+
+// WithDeleted returns a view of the collection whose queries include soft-deleted documents.
+func (col *Collection[T]) WithDeleted() *Collection[T] {
+	view := *col
+	view.deletedScope = withDeleted
+	return &view
+}
+
+// OnlyDeleted returns a view of the collection whose queries match soft-deleted documents only.
+func (col *Collection[T]) OnlyDeleted() *Collection[T] {
+	view := *col
+	view.deletedScope = onlyDeleted
+	return &view
+}
+
+func (col *Collection[T]) Restore(logger Logger, filter interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, ctxCancel := col.newContext(logger)
+	defer ctxCancel()
+	return col.restore(logger, ctx, filter, opts...)
+}
+
+func (col *Collection[T]) RestoreWithTrx(logger Logger, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return col.restore(logger, *sessCtx, filter, opts...)
+}
+
+// Purge permanently removes soft-deleted documents matching the filter.
+func (col *Collection[T]) Purge(logger Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, ctxCancel := col.newContext(logger)
+	defer ctxCancel()
+	return col.purge(logger, ctx, filter, opts...)
+}
+
+func (col *Collection[T]) PurgeWithTrx(logger Logger, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return col.purge(logger, *sessCtx, filter, opts...)
+}
+
+func (col *Collection[T]) restore(logger Logger, ctx context.Context, filter interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if col.softDeleteField == "" {
+		return nil, errorType.SoftDeleteDisabledErr
+	}
+	update := bson.M{"$unset": bson.M{col.softDeleteField: ""}}
+	updateResult, err := col.OnlyDeleted().updateMany(logger, ctx, filter, update, opts...)
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil), "Restore")
+	}
+	if updateResult.MatchedCount == 0 {
+		return updateResult, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, update, nil), "Restore")
+	}
+	return updateResult, nil
+}
+
+func (col *Collection[T]) purge(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	if col.softDeleteField == "" {
+		return nil, errorType.SoftDeleteDisabledErr
+	}
+	deleteResult, err := col.purgeMany(logger, ctx, col.deletedFilter(filter, onlyDeleted), opts...)
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "Purge")
+	}
+	if deleteResult.DeletedCount == 0 {
+		return deleteResult, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil), "Purge")
+	}
+	return deleteResult, nil
+}
+
+func (col *Collection[T]) softDeleteOne(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	filter = col.deletedFilter(filter, excludeDeleted)
+	update := col.incrementVersion(col.stampUpdate(col.softDeleteUpdate(), false))
+	ctx, startTime := col.startQuery(ctx)
+	if col.auditEnabled() {
+		singleResult := col.Collection.FindOneAndUpdate(ctx, filter, update, updateToFindOneAndUpdateOptions(deleteToUpdateOptions(opts...)))
+		updateResult, before, err := findAndModifyResult(singleResult, nil)
+		col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "deleteOne", Filter: filter}, err)
+		if err != nil {
+			return nil, err
+		}
+		return &mongo.DeleteResult{DeletedCount: updateResult.ModifiedCount}, col.auditOne(ctx, AuditDelete, filter, update, nil, updateResult, before)
+	}
+	updateResult, err := col.Collection.UpdateOne(ctx, filter, update, deleteToUpdateOptions(opts...))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "deleteOne", Filter: filter}, err)
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: updateResult.ModifiedCount}, nil
+}
+
+func (col *Collection[T]) softDeleteMany(logger Logger, ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	filter = col.deletedFilter(filter, excludeDeleted)
+	update := col.incrementVersion(col.stampUpdate(col.softDeleteUpdate(), false))
+	deleteOpts := options.MergeDeleteOptions(opts...)
+	ids, err := col.matchedIds(logger, ctx, filter, deleteOpts.Collation, deleteOpts.Hint)
+	if err != nil {
+		return nil, err
+	}
+	ctx, startTime := col.startQuery(ctx)
+	updateResult, err := col.Collection.UpdateMany(ctx, filter, update, deleteToUpdateOptions(opts...))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfMany(), SlowQueryEvent{Operation: "deleteMany", Filter: filter}, err)
+	if err != nil {
+		return nil, err
+	}
+	return &mongo.DeleteResult{DeletedCount: updateResult.ModifiedCount}, col.auditUpdate(ctx, AuditDelete, filter, update, nil, updateResult, ids)
+}
+
+func (col *Collection[T]) softFindOneAndDelete(logger Logger, ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) (*mongo.SingleResult, error) {
+	filter = col.deletedFilter(filter, excludeDeleted)
+	update := col.incrementVersion(col.stampUpdate(col.softDeleteUpdate(), false))
+	ctx, startTime := col.startQuery(ctx)
+	singleResult := col.Collection.FindOneAndUpdate(ctx, filter, update, findOneAndDeleteToUpdateOptions(opts...))
+	col.observe(logger, ctx, startTime, logger.GetSlowQueryDurationOfOne(), SlowQueryEvent{Operation: "findOneAndDelete", Filter: filter}, singleResult.Err())
+	return col.auditSingleResult(ctx, singleResult, AuditDelete, filter, update, nil, false)
+}
+
+func (col *Collection[T]) softDeleteUpdate() bson.M {
+	return bson.M{"$currentDate": bson.M{col.softDeleteField: true}}
+}
+
+// scopeFilter restricts the filter to the documents visible in the current deleted scope.
+func (col *Collection[T]) scopeFilter(filter interface{}) interface{} {
+	if col.softDeleteField == "" {
+		return filter
+	}
+	return col.deletedFilter(filter, col.deletedScope)
+}
+
+func (col *Collection[T]) deletedFilter(filter interface{}, scope deletedScope) interface{} {
+	condition := col.deletedCondition(scope)
+	if condition == nil {
+		return filter
+	}
+	if filter == nil {
+		return condition
+	}
+	return bson.D{{Key: "$and", Value: bson.A{filter, condition}}}
+}
+
+func (col *Collection[T]) deletedCondition(scope deletedScope) bson.M {
+	switch scope {
+	case excludeDeleted:
+		return bson.M{col.softDeleteField: nil}
+	case onlyDeleted:
+		return bson.M{col.softDeleteField: bson.M{"$ne": nil}}
+	}
+	return nil
+}
+
+// scopePipeline prepends a $match stage for the current deleted scope, after any stage that must come first.
+// A pipeline that is not a slice of stages is marshaled to an array first, and one that is not an array fails
+// with PipelineScopeErr instead of running unscoped.
+func (col *Collection[T]) scopePipeline(pipeline interface{}) (interface{}, error) {
+	if col.softDeleteField == "" {
+		return pipeline, nil
+	}
+	condition := col.deletedCondition(col.deletedScope)
+	if condition == nil {
+		return pipeline, nil
+	}
+	stages, err := col.pipelineStages(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	match := bson.D{{Key: "$match", Value: condition}}
+	if len(stages) > 0 && leadingStages[stageName(stages[0])] {
+		return append(bson.A{stages[0], match}, stages[1:]...), nil
+	}
+	return append(bson.A{match}, stages...), nil
+}
+
+// pipelineStages returns the stages of a pipeline. The stages of a slice are kept as they are,
+// and the stages of other pipelines, such as a ValueMarshaler or bsoncore.Array, are raw documents.
+// A ValueMarshaler comes first, as in the driver.
+func (col *Collection[T]) pipelineStages(pipeline interface{}) (bson.A, error) {
+	value := reflect.ValueOf(pipeline)
+	switch pipeline.(type) {
+	case bsoncodec.ValueMarshaler, bson.D, bson.Raw, bsoncore.Document, bsoncore.Array:
+	default:
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+			stages := make(bson.A, 0, value.Len()+1)
+			for i := 0; i < value.Len(); i++ {
+				stages = append(stages, value.Index(i).Interface())
+			}
+			return stages, nil
+		}
+	}
+
+	var data []byte
+	if array, ok := pipeline.(bsoncore.Array); ok {
+		data = array
+	} else {
+		registry := col.registry
+		if registry == nil {
+			registry = bson.DefaultRegistry
+		}
+		valueType, marshaled, err := bson.MarshalValueWithRegistry(registry, pipeline)
+		if err != nil {
+			return nil, errors.Wrap(errorType.PipelineScopeErr, err.Error())
+		}
+		if valueType != bsontype.Array {
+			return nil, errors.Wrapf(errorType.PipelineScopeErr, "%T is not an array of stages", pipeline)
+		}
+		data = marshaled
+	}
+	values, err := bson.Raw(data).Values()
+	if err != nil {
+		return nil, errors.Wrap(errorType.PipelineScopeErr, err.Error())
+	}
+	stages := make(bson.A, 0, len(values)+1)
+	for _, value := range values {
+		stage, ok := value.DocumentOK()
+		if !ok {
+			return nil, errors.Wrapf(errorType.PipelineScopeErr, "a stage is a %s", value.Type)
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+func stageName(stage interface{}) string {
+	raw, err := bson.Marshal(stage)
+	if err != nil {
+		return ""
+	}
+	element, err := bson.Raw(raw).IndexErr(0)
+	if err != nil {
+		return ""
+	}
+	return element.Key()
+}
+
+func deleteToUpdateOptions(opts ...*options.DeleteOptions) *options.UpdateOptions {
+	deleteOpts := options.MergeDeleteOptions(opts...)
+	updateOpts := options.Update()
+	updateOpts.Collation = deleteOpts.Collation
+	updateOpts.Comment = deleteOpts.Comment
+	updateOpts.Hint = deleteOpts.Hint
+	updateOpts.Let = deleteOpts.Let
+	return updateOpts
+}
+
+func findOneAndDeleteToUpdateOptions(opts ...*options.FindOneAndDeleteOptions) *options.FindOneAndUpdateOptions {
+	deleteOpts := options.MergeFindOneAndDeleteOptions(opts...)
+	updateOpts := options.FindOneAndUpdate()
+	updateOpts.Collation = deleteOpts.Collation
+	updateOpts.Comment = deleteOpts.Comment
+	updateOpts.MaxTime = deleteOpts.MaxTime
+	updateOpts.Projection = deleteOpts.Projection
+	updateOpts.Sort = deleteOpts.Sort
+	updateOpts.Hint = deleteOpts.Hint
+	updateOpts.Let = deleteOpts.Let
+	return updateOpts
+}
+
+func estimatedToCountOptions(opts ...*options.EstimatedDocumentCountOptions) *options.CountOptions {
+	estimatedOpts := options.MergeEstimatedDocumentCountOptions(opts...)
+	countOpts := options.Count()
+	if comment, ok := estimatedOpts.Comment.(string); ok {
+		countOpts.SetComment(comment)
+	}
+	countOpts.MaxTime = estimatedOpts.MaxTime
+	return countOpts
+}
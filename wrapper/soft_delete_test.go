@@ -0,0 +1,172 @@
+package wrapper
+
+import (
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func newSoftDeleteCollection(t *mtest.T) *Collection[account] {
+	return NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetSoftDelete(true))
+}
+
+func Test_SoftDelete(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("delete one marks the document", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		result, err := col.DeleteOne(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.DeletedCount)
+
+		started := t.GetStartedEvent()
+		assert.Equal(t, "update", started.CommandName)
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "$and", update.Lookup("q").Document().Index(0).Key())
+		_, err = update.LookupErr("u", "$currentDate", "deletedAt")
+		assert.NoError(t, err)
+	})
+
+	mt.Run("delete many without match is not found", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		_, err := col.DeleteMany(logger, bson.M{"account_id": 1})
+		assert.True(t, errorType.IsNotFoundErr(err))
+	})
+
+	mt.Run("find excludes deleted documents", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+		t.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := col.FindAll(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+
+		filter := t.GetStartedEvent().Command.Lookup("filter").Document()
+		condition := filter.Lookup("$and").Array().Index(1).Value().Document()
+		assert.Equal(t, bson.TypeNull, condition.Lookup("deletedAt").Type)
+	})
+
+	mt.Run("with deleted keeps the filter", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t).WithDeleted()
+		t.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := col.FindAll(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+
+		filter := t.GetStartedEvent().Command.Lookup("filter").Document()
+		_, err = filter.LookupErr("$and")
+		assert.Error(t, err)
+	})
+
+	mt.Run("only deleted", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t).OnlyDeleted()
+		t.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := col.Aggregate(logger, mongo.Pipeline{{{Key: "$sort", Value: bson.M{"account_id": 1}}}})
+		assert.NoError(t, err)
+
+		pipeline := t.GetStartedEvent().Command.Lookup("pipeline").Array()
+		_, err = pipeline.Index(0).Value().Document().LookupErr("$match", "deletedAt", "$ne")
+		assert.NoError(t, err)
+	})
+
+	mt.Run("purge removes deleted documents", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+
+		result, err := col.Purge(logger, bson.M{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), result.DeletedCount)
+		assert.Equal(t, "delete", t.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("update excludes deleted documents", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+		t.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		_, err := col.UpdateOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 1}})
+		assert.NoError(t, err)
+		filter := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, bson.TypeNull, filter.Lookup("$and").Array().Index(1).Value().Document().Lookup("deletedAt").Type)
+
+		_, err = col.ReplaceOne(logger, bson.M{"account_id": 1}, account{AccountId: 1})
+		assert.NoError(t, err)
+		filter = t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, bson.TypeNull, filter.Lookup("$and").Array().Index(1).Value().Document().Lookup("deletedAt").Type)
+	})
+
+	mt.Run("restore matches only deleted documents", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		_, err := col.Restore(logger, bson.M{"account_id": 1})
+		assert.NoError(t, err)
+		filter := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		_, err = filter.Lookup("$and").Array().Index(1).Value().Document().LookupErr("deletedAt", "$ne")
+		assert.NoError(t, err)
+	})
+
+	mt.Run("aggregate fails on a pipeline it cannot scope", func(t *mtest.T) {
+		col := newSoftDeleteCollection(t)
+
+		_, err := col.Aggregate(logger, bson.M{"$sort": bson.M{"account_id": 1}})
+		assert.ErrorIs(t, err, errorType.PipelineScopeErr)
+	})
+
+	mt.Run("restore requires soft delete", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+
+		_, err := col.Restore(logger, bson.M{})
+		assert.ErrorIs(t, err, errorType.SoftDeleteDisabledErr)
+	})
+}
+
+// rawPipeline is a pipeline that marshals itself, which the driver accepts as a ValueMarshaler.
+type rawPipeline bson.A
+
+func (p rawPipeline) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(bson.A(p))
+}
+
+func Test_scopePipeline(t *testing.T) {
+	col := &Collection[account]{softDeleteField: defaultSoftDeleteField}
+	geoNear := bson.D{{Key: "$geoNear", Value: bson.M{}}}
+	sort := bson.D{{Key: "$sort", Value: bson.M{"account_id": 1}}}
+
+	scoped, err := col.scopePipeline(mongo.Pipeline{geoNear, sort})
+	assert.NoError(t, err)
+	assert.Len(t, scoped, 3)
+	assert.Equal(t, geoNear, scoped.(bson.A)[0])
+	assert.Equal(t, "$match", scoped.(bson.A)[1].(bson.D)[0].Key)
+	assert.Equal(t, sort, scoped.(bson.A)[2])
+
+	_, array, err := bson.MarshalValue(bson.A{sort})
+	assert.NoError(t, err)
+	for _, pipeline := range []interface{}{bsoncore.Array(array), rawPipeline{sort}} {
+		scoped, err := col.scopePipeline(pipeline)
+		assert.NoError(t, err)
+		assert.Len(t, scoped, 2)
+		assert.Equal(t, "$match", scoped.(bson.A)[0].(bson.D)[0].Key)
+		assert.Equal(t, "$sort", scoped.(bson.A)[1].(bson.Raw).Index(0).Key())
+	}
+
+	for _, pipeline := range []interface{}{sort, bson.M{"$sort": bson.M{"account_id": 1}}, rawPipeline{1}} {
+		_, err := col.scopePipeline(pipeline)
+		assert.ErrorIs(t, err, errorType.PipelineScopeErr)
+	}
+}
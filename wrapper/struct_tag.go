@@ -0,0 +1,65 @@
+package wrapper
+
+import (
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+)
+
+const mongoTagKey = "mongo"
+
+// documentTypeOf returns the type of T, whose fields tagged `mongo:"sensitive"` are redacted in errors and slow queries.
+func documentTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// taggedFieldName returns the bson name of the first field of T tagged `mongo:"<option>"`, including inline structs.
+func taggedFieldName[T any](option string) string {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	return findTaggedField(t, option)
+}
+
+func findTaggedField(t reflect.Type, option string) string {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tags, err := bsoncodec.DefaultStructTagParser(field)
+		if err != nil || tags.Skip {
+			continue
+		}
+		if tags.Inline {
+			inlineType := field.Type
+			for inlineType.Kind() == reflect.Pointer {
+				inlineType = inlineType.Elem()
+			}
+			if inlineType.Kind() == reflect.Struct {
+				if name := findTaggedField(inlineType, option); name != "" {
+					return name
+				}
+			}
+			continue
+		}
+		if hasMongoTag(field, option) {
+			return tags.Name
+		}
+	}
+	return ""
+}
+
+func hasMongoTag(field reflect.StructField, option string) bool {
+	for _, value := range strings.Split(field.Tag.Get(mongoTagKey), ",") {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package wrapper
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultCreatedAtField = "createdAt"
+	defaultUpdatedAtField = "updatedAt"
+
+	createdAtTag = "createdAt"
+	updatedAtTag = "updatedAt"
+)
+
+func (col *Collection[T]) timestampsEnabled() bool {
+	return col.updatedAtField != ""
+}
+
+// stampInsert sets the creation and update time of a document unless they are already set.
+func (col *Collection[T]) stampInsert(document interface{}) interface{} {
+	if !col.timestampsEnabled() {
+		return document
+	}
+	doc, err := toDocument(col.registry, document)
+	if err != nil {
+		// let the driver report the invalid document
+		return document
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	doc = setFieldIfEmpty(doc, col.createdAtField, now)
+	doc = setFieldIfEmpty(doc, col.updatedAtField, now)
+	return doc
+}
+
+func (col *Collection[T]) stampInsertMany(documents []interface{}) []interface{} {
+	if !col.timestampsEnabled() {
+		return documents
+	}
+	stamped := make([]interface{}, len(documents))
+	for i, document := range documents {
+		stamped[i] = col.stampInsert(document)
+	}
+	return stamped
+}
+
+// stampUpdate adds $currentDate for the update time, and $setOnInsert for the creation time of upserts.
+// Fields that the update already writes are left alone.
+func (col *Collection[T]) stampUpdate(update interface{}, upsert bool) interface{} {
+	if !col.timestampsEnabled() {
+		return update
+	}
+	doc, pipeline, ok := toUpdate(update)
+	if !ok {
+		return update
+	}
+	updated := updatedFields(doc, pipeline)
+
+	if pipeline != nil {
+		set := bson.D{}
+		if !updated[col.updatedAtField] {
+			set = append(set, bson.E{Key: col.updatedAtField, Value: "$$NOW"})
+		}
+		if upsert && !updated[col.createdAtField] {
+			set = append(set, bson.E{Key: col.createdAtField, Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + col.createdAtField, "$$NOW"}}}})
+		}
+		if len(set) == 0 {
+			return pipeline
+		}
+		return append(pipeline, bson.D{{Key: "$set", Value: set}})
+	}
+
+	if !updated[col.updatedAtField] {
+		doc = addOperand(doc, "$currentDate", bson.E{Key: col.updatedAtField, Value: true})
+	}
+	if upsert && !updated[col.createdAtField] {
+		doc = addOperand(doc, "$setOnInsert", bson.E{Key: col.createdAtField, Value: primitive.NewDateTimeFromTime(time.Now())})
+	}
+	return doc
+}
+
+// stampReplacement sets the update time of a replacement.
+func (col *Collection[T]) stampReplacement(replacement interface{}) interface{} {
+	if !col.timestampsEnabled() {
+		return replacement
+	}
+	doc, err := toDocument(col.registry, replacement)
+	if err != nil {
+		return replacement
+	}
+	return setField(doc, col.updatedAtField, primitive.NewDateTimeFromTime(time.Now()))
+}
+
+// keepCreatedAt returns the update pipeline that writes a stamped replacement without a creation time, or nil if the
+// replacement has one. Replacing would clear the creation time, so the pipeline replaces the document and keeps
+// the creation time of the stored document in the same write, or sets the update time as the creation time if
+// the replacement is upserted.
+func (col *Collection[T]) keepCreatedAt(replacement interface{}) bson.A {
+	if !col.timestampsEnabled() {
+		return nil
+	}
+	doc, ok := replacement.(bson.D)
+	if !ok || !isEmptyField(doc, col.createdAtField) {
+		return nil
+	}
+	updatedAt, _ := lookup(doc, col.updatedAtField)
+	createdAt := bson.D{{Key: col.createdAtField, Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + col.createdAtField, updatedAt}}}}}
+	return bson.A{bson.D{{Key: "$replaceWith", Value: bson.D{{Key: "$mergeObjects", Value: bson.A{
+		bson.D{{Key: "$literal", Value: doc}},
+		createdAt,
+	}}}}}}
+}
+
+func replaceToUpdateOptions(opts ...*options.ReplaceOptions) *options.UpdateOptions {
+	replaceOpts := options.MergeReplaceOptions(opts...)
+	updateOpts := options.Update()
+	updateOpts.BypassDocumentValidation = replaceOpts.BypassDocumentValidation
+	updateOpts.Collation = replaceOpts.Collation
+	updateOpts.Comment = replaceOpts.Comment
+	updateOpts.Hint = replaceOpts.Hint
+	updateOpts.Upsert = replaceOpts.Upsert
+	updateOpts.Let = replaceOpts.Let
+	return updateOpts
+}
+
+func findOneAndReplaceToUpdateOptions(opts ...*options.FindOneAndReplaceOptions) *options.FindOneAndUpdateOptions {
+	replaceOpts := options.MergeFindOneAndReplaceOptions(opts...)
+	updateOpts := options.FindOneAndUpdate()
+	updateOpts.BypassDocumentValidation = replaceOpts.BypassDocumentValidation
+	updateOpts.Collation = replaceOpts.Collation
+	updateOpts.Comment = replaceOpts.Comment
+	updateOpts.MaxTime = replaceOpts.MaxTime
+	updateOpts.Projection = replaceOpts.Projection
+	updateOpts.ReturnDocument = replaceOpts.ReturnDocument
+	updateOpts.Sort = replaceOpts.Sort
+	updateOpts.Upsert = replaceOpts.Upsert
+	updateOpts.Hint = replaceOpts.Hint
+	updateOpts.Let = replaceOpts.Let
+	return updateOpts
+}
+
+func (col *Collection[T]) stampModels(models []mongo.WriteModel) []mongo.WriteModel {
+	if !col.timestampsEnabled() {
+		return models
+	}
+	stamped := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		switch m := model.(type) {
+		case *mongo.InsertOneModel:
+			copied := *m
+			copied.Document = col.stampInsert(m.Document)
+			stamped[i] = &copied
+		case *mongo.UpdateOneModel:
+			copied := *m
+			copied.Update = col.stampUpdate(m.Update, boolValue(m.Upsert))
+			stamped[i] = &copied
+		case *mongo.UpdateManyModel:
+			copied := *m
+			copied.Update = col.stampUpdate(m.Update, boolValue(m.Upsert))
+			stamped[i] = &copied
+		case *mongo.ReplaceOneModel:
+			copied := *m
+			copied.Replacement = col.stampReplacement(m.Replacement)
+			stamped[i] = &copied
+		default:
+			stamped[i] = model
+		}
+	}
+	return stamped
+}
+
+// keepCreatedAtOfModels turns the stamped replace models without a creation time into update models with
+// the pipeline of keepCreatedAt.
+func (col *Collection[T]) keepCreatedAtOfModels(models []mongo.WriteModel) []mongo.WriteModel {
+	if !col.timestampsEnabled() {
+		return models
+	}
+	written := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		written[i] = model
+		if m, ok := model.(*mongo.ReplaceOneModel); ok {
+			if pipeline := col.keepCreatedAt(m.Replacement); pipeline != nil {
+				written[i] = &mongo.UpdateOneModel{Filter: m.Filter, Update: pipeline, Collation: m.Collation, Upsert: m.Upsert, Hint: m.Hint}
+			}
+		}
+	}
+	return written
+}
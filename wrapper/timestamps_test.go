@@ -0,0 +1,273 @@
+package wrapper
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditedAccount struct {
+	AccountId int       `bson:"account_id"`
+	Created   time.Time `bson:"created" mongo:"createdAt"`
+	Modified  time.Time `bson:"modified" mongo:"updatedAt"`
+}
+
+func newTimestampCollection() *Collection[auditedAccount] {
+	col := &Collection[auditedAccount]{}
+	col.applyOptions(NewCollectionOptions().SetTimestamps(true))
+	return col
+}
+
+func Test_TimestampFields(t *testing.T) {
+	col := newTimestampCollection()
+	assert.Equal(t, "created", col.createdAtField)
+	assert.Equal(t, "modified", col.updatedAtField)
+
+	untagged := &Collection[account]{}
+	untagged.applyOptions(NewCollectionOptions().SetTimestamps(true).SetUpdatedAtField("updated_at"))
+	assert.Equal(t, defaultCreatedAtField, untagged.createdAtField)
+	assert.Equal(t, "updated_at", untagged.updatedAtField)
+
+	disabled := &Collection[auditedAccount]{}
+	disabled.applyOptions(NewCollectionOptions())
+	assert.False(t, disabled.timestampsEnabled())
+}
+
+func Test_stampInsert(t *testing.T) {
+	col := newTimestampCollection()
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	doc := col.stampInsert(auditedAccount{AccountId: 1, Created: createdAt}).(bson.D)
+	created, _ := lookup(doc, "created")
+	modified, _ := lookup(doc, "modified")
+	assert.Equal(t, primitive.NewDateTimeFromTime(createdAt), created)
+	assert.NotEqual(t, zeroDateTime, modified)
+
+	doc = col.stampInsert(bson.M{"account_id": 1}).(bson.D)
+	_, ok := lookup(doc, "created")
+	assert.True(t, ok)
+}
+
+func Test_stampUpdate(t *testing.T) {
+	col := newTimestampCollection()
+
+	t.Run("update document", func(t *testing.T) {
+		update := col.stampUpdate(bson.M{"$set": bson.M{"account_id": 2}}, false).(bson.D)
+		currentDate, ok := lookup(update, "$currentDate")
+		assert.True(t, ok)
+		assert.Equal(t, bson.D{{Key: "modified", Value: true}}, currentDate)
+		_, ok = lookup(update, "$setOnInsert")
+		assert.False(t, ok)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		update := col.stampUpdate(bson.D{{Key: "$setOnInsert", Value: bson.D{{Key: "account_id", Value: 2}}}}, true).(bson.D)
+		setOnInsert, _ := lookup(update, "$setOnInsert")
+		assert.Len(t, setOnInsert, 2)
+		assert.Equal(t, "created", setOnInsert.(bson.D)[1].Key)
+	})
+
+	t.Run("field already written", func(t *testing.T) {
+		update := col.stampUpdate(bson.M{"$set": bson.M{"modified": time.Now()}}, false).(bson.D)
+		_, ok := lookup(update, "$currentDate")
+		assert.False(t, ok)
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		update := col.stampUpdate(mongo.Pipeline{{{Key: "$set", Value: bson.M{"account_id": 2}}}}, false).(bson.A)
+		assert.Len(t, update, 2)
+		assert.Equal(t, bson.D{{Key: "$set", Value: bson.D{{Key: "modified", Value: "$$NOW"}}}}, update[1])
+	})
+
+	t.Run("replacement style update is left to the driver", func(t *testing.T) {
+		update := bson.M{"account_id": 2}
+		assert.Equal(t, update, col.stampUpdate(update, false))
+	})
+}
+
+func Test_stampReplacement(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	modifiedAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mt.Run("keeps the stored creation time in the same write", func(t *mtest.T) {
+		col := NewCollection[auditedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		_, err := col.ReplaceOne(logger, bson.M{"account_id": 1}, auditedAccount{AccountId: 1, Modified: modifiedAt}, options.Replace().SetUpsert(true))
+		assert.NoError(t, err)
+		started := t.GetStartedEvent()
+		assert.Equal(t, "update", started.CommandName)
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.True(t, update.Lookup("upsert").Boolean())
+		merged := update.Lookup("u").Array().Index(0).Value().Document().Lookup("$replaceWith", "$mergeObjects").Array()
+		replacement := merged.Index(0).Value().Document().Lookup("$literal").Document()
+		assert.Equal(t, int32(1), replacement.Lookup("account_id").Int32())
+		assert.NotEqual(t, modifiedAt, replacement.Lookup("modified").Time().UTC())
+		ifNull := merged.Index(1).Value().Document().Lookup("created", "$ifNull").Array()
+		assert.Equal(t, "$created", ifNull.Index(0).Value().StringValue())
+		assert.Equal(t, replacement.Lookup("modified"), ifNull.Index(1).Value())
+		assert.Nil(t, t.GetStartedEvent(), "no lookup of the stored document")
+	})
+
+	mt.Run("set by the caller", func(t *mtest.T) {
+		col := NewCollection[auditedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		_, err := col.ReplaceOne(logger, bson.M{"account_id": 1}, auditedAccount{AccountId: 1, Created: createdAt})
+		assert.NoError(t, err)
+		replaced := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		assert.Equal(t, createdAt, replaced.Lookup("created").Time().UTC())
+		assert.False(t, replaced.Lookup("modified").Time().IsZero())
+	})
+
+	mt.Run("find one and replace", func(t *mtest.T) {
+		col := NewCollection[auditedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "account_id", Value: 1}}}))
+
+		var replaced auditedAccount
+		err := col.FindOneAndReplace(logger, &replaced, bson.M{"account_id": 1}, auditedAccount{AccountId: 1},
+			options.FindOneAndReplace().SetReturnDocument(options.After))
+		assert.NoError(t, err)
+		command := t.GetStartedEvent().Command
+		assert.Equal(t, bson.TypeArray, command.Lookup("update").Type)
+		assert.True(t, command.Lookup("new").Boolean())
+	})
+
+	mt.Run("bulk write", func(t *mtest.T) {
+		col := NewCollection[auditedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+
+		models := []mongo.WriteModel{
+			mongo.NewReplaceOneModel().SetFilter(bson.M{"account_id": 1}).SetReplacement(auditedAccount{AccountId: 1}),
+			mongo.NewReplaceOneModel().SetFilter(bson.M{"account_id": 2}).SetReplacement(auditedAccount{AccountId: 2, Created: createdAt}),
+		}
+		_, err := col.BulkWrite(logger, models, options.BulkWrite())
+		assert.NoError(t, err)
+		updates := t.GetStartedEvent().Command.Lookup("updates").Array()
+		assert.Equal(t, bson.TypeArray, updates.Index(0).Value().Document().Lookup("u").Type)
+		assert.Equal(t, createdAt, updates.Index(1).Value().Document().Lookup("u", "created").Time().UTC())
+		assert.Nil(t, t.GetStartedEvent(), "one write for the models")
+	})
+}
+
+type registryAccount struct {
+	AccountId int `bson:"account_id"`
+	Balance   int `bson:"balance"`
+}
+
+func Test_SetRegistry(t *testing.T) {
+	// the registry marshals every int as a string
+	registry := bson.NewRegistryBuilder().RegisterTypeEncoder(reflect.TypeOf(0), bsoncodec.ValueEncoderFunc(
+		func(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, v reflect.Value) error {
+			return vw.WriteString(strconv.FormatInt(v.Int(), 10))
+		})).Build()
+	col := &Collection[registryAccount]{}
+	col.applyOptions(NewCollectionOptions().SetTimestamps(true).SetRegistry(registry))
+
+	doc := col.stampInsert(registryAccount{AccountId: 1}).(bson.D)
+	accountId, _ := lookup(doc, "account_id")
+	assert.Equal(t, "1", accountId)
+}
+
+func Test_Timestamps(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("insert one", func(t *mtest.T) {
+		col := NewCollection[auditedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse())
+
+		_, err := col.InsertOne(logger, auditedAccount{AccountId: 1})
+		assert.NoError(t, err)
+
+		inserted := t.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.False(t, inserted.Lookup("created").Time().IsZero())
+		assert.False(t, inserted.Lookup("modified").Time().IsZero())
+	})
+
+	mt.Run("bulk write", func(t *mtest.T) {
+		col := NewCollection[auditedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		models := []mongo.WriteModel{
+			mongo.NewUpdateOneModel().SetFilter(bson.M{"account_id": 1}).SetUpdate(bson.M{"$set": bson.M{"account_id": 2}}).SetUpsert(true),
+		}
+		_, err := col.BulkWrite(logger, models, options.BulkWrite())
+		assert.NoError(t, err)
+
+		update := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		_, err = update.LookupErr("$currentDate", "modified")
+		assert.NoError(t, err)
+		_, err = update.LookupErr("$setOnInsert", "created")
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{"$set": bson.M{"account_id": 2}}, models[0].(*mongo.UpdateOneModel).Update)
+	})
+
+	type credential struct {
+		AccountId int    `bson:"account_id"`
+		Token     string `bson:"token" mongo:"sensitive"`
+	}
+
+	mt.Run("sensitive fields of stamped documents are redacted in slow queries", func(t *mtest.T) {
+		slowLogger := &slowLogger{events: make(chan SlowQueryEvent, 3)}
+		col := NewCollection[credential](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetTimestamps(true))
+		t.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "account_id", Value: 1}}}),
+		)
+
+		_, err := col.InsertOne(slowLogger, credential{AccountId: 1, Token: "secret"})
+		assert.NoError(t, err)
+		_, err = col.InsertMany(slowLogger, []interface{}{credential{AccountId: 1, Token: "secret"}})
+		assert.NoError(t, err)
+		var replaced credential
+		assert.NoError(t, col.FindOneAndReplace(slowLogger, &replaced, bson.M{"account_id": 1}, credential{AccountId: 1, Token: "secret"}))
+
+		for i := 0; i < 3; i++ {
+			event := <-slowLogger.events
+			assert.NotContains(t, event.String(), "secret", event.Operation)
+			assert.Contains(t, event.String(), `"token": "[REDACTED]"`, event.Operation)
+		}
+	})
+
+	mt.Run("sensitive fields of the document type are redacted in bson.M filters", func(t *mtest.T) {
+		slowLogger := &slowLogger{events: make(chan SlowQueryEvent, 1)}
+		col := NewCollection[credential](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(
+			mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+		)
+
+		var found credential
+		err := col.FindOne(slowLogger, &found, bson.M{"token": "secret"})
+		assert.True(t, errorType.IsNotFoundErr(err))
+		assert.NotContains(t, err.Error(), "secret")
+		assert.Equal(t, `{"token": "[REDACTED]"}`, errorType.Fields(err)[errorType.FieldFilter])
+		event := <-slowLogger.events
+		assert.NotContains(t, event.String(), "secret")
+		assert.Contains(t, event.String(), `filter: {"token": "[REDACTED]"}`)
+
+		_, err = col.UpdateOne(slowLogger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"token": "secret"}})
+		assert.True(t, errorType.IsNotFoundErr(err))
+		assert.NotContains(t, err.Error(), "secret")
+		event = <-slowLogger.events
+		assert.NotContains(t, event.String(), "secret")
+	})
+}
@@ -0,0 +1,126 @@
+package wrapper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transaction runs fn in a transaction and returns its result once the transaction is committed.
+// Like Client.Transaction, fn is retried on transient errors and the commit on unknown commit results.
+// The error is classified by errorType.ParseAndReturnTransactionError: errors.Is(err, errorType.ErrUnknownCommitResult)
+// means the transaction may have been applied, and errorType.ErrTransientTransaction that it can be run again.
+// Errors that fn returns on its own are returned as they are.
+//
+// If ctx is a session context of the client with a running transaction, fn joins it by default and the outermost
+// transaction commits. See Propagation for the other modes. If fn fails in a transaction it joined, the transaction
+// is rollback-only: the outermost transaction aborts with errorType.RollbackOnlyErr even if its function ignores the error.
+//
+//	account, err := wrapper.Transaction(ctx, client, func(sessCtx mongo.SessionContext) (*Account, error) {
+//		...
+//	}, wrapper.NewTransactionOptions().SetTimeout(5*time.Second))
+func Transaction[R any](ctx context.Context, client *Client, fn func(sessCtx mongo.SessionContext) (R, error), opts ...*TransactionOptions) (R, error) {
+	var zero R
+	result, err := client.transaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return fn(sessCtx)
+	}, mergeTransactionOptions(opts...))
+	if err != nil {
+		return zero, errorType.ParseAndReturnTransactionError(err)
+	}
+	r, _ := result.(R)
+	return r, nil
+}
+
+func (client *Client) transaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) (interface{}, error), opt *TransactionOptions) (interface{}, error) {
+	if opt.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *opt.Timeout)
+		defer cancel()
+	}
+
+	outer := client.runningTransaction(ctx)
+	switch *opt.Propagation {
+	case PropagationRequired, PropagationSupported:
+		if outer != nil {
+			result, err := fn(mongo.NewSessionContext(ctx, outer))
+			if err != nil {
+				markRollbackOnly(ctx, err)
+			}
+			return result, err
+		}
+	case PropagationNever:
+		if outer != nil {
+			return nil, errorType.TransactionExistsErr
+		}
+	}
+
+	session, err := client.Client.StartSession(opt.Session)
+	if err != nil {
+		return nil, errorType.MongoClientError(err)
+	}
+	defer session.EndSession(context.Background())
+
+	if *opt.Propagation == PropagationSupported || *opt.Propagation == PropagationNever {
+		return fn(mongo.NewSessionContext(ctx, session))
+	}
+	return session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// a new mark for each attempt, since a retry runs the joined transactions again
+		mark := &rollbackOnly{}
+		result, err := fn(mongo.NewSessionContext(context.WithValue(sessCtx, rollbackOnlyKey{}, mark), session))
+		if err == nil {
+			err = mark.err()
+		}
+		return result, err
+	}, opt.Transaction)
+}
+
+type rollbackOnlyKey struct{}
+
+// rollbackOnly is the mark of a transaction that a joined transaction failed in.
+type rollbackOnly struct {
+	mu    sync.Mutex
+	cause error
+}
+
+func (r *rollbackOnly) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cause == nil {
+		return nil
+	}
+	return errors.Wrap(errorType.RollbackOnlyErr, r.cause.Error())
+}
+
+// markRollbackOnly marks the transaction of ctx, so that the outermost transaction aborts.
+func markRollbackOnly(ctx context.Context, cause error) {
+	mark, ok := ctx.Value(rollbackOnlyKey{}).(*rollbackOnly)
+	if !ok {
+		return
+	}
+	mark.mu.Lock()
+	defer mark.mu.Unlock()
+	if mark.cause == nil {
+		mark.cause = cause
+	}
+}
+
+// runningTransaction returns the session of ctx if it is a session of the client with a running transaction, or nil.
+func (client *Client) runningTransaction(ctx context.Context) mongo.Session {
+	session := mongo.SessionFromContext(ctx)
+	if session == nil || session.Client() != client.Client {
+		return nil
+	}
+	if inTransaction(ctx) {
+		return session
+	}
+	return nil
+}
+
+// inTransaction reports whether ctx is a session context with a running transaction.
+func inTransaction(ctx context.Context) bool {
+	xSession, ok := mongo.SessionFromContext(ctx).(mongo.XSession)
+	return ok && xSession.ClientSession().TransactionRunning()
+}
@@ -0,0 +1,87 @@
+package wrapper
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Propagation decides how a transaction runs when the context already has one.
+type Propagation int
+
+const (
+	// Joins the transaction of the context, or starts a new one. Only the outermost transaction commits,
+	// and it aborts if a transaction that joined it failed.
+	PropagationRequired Propagation = iota
+	// Starts a new transaction in a new session, which commits on its own even inside another transaction.
+	PropagationRequiresNew
+	// Joins the transaction of the context, or runs in a session without a transaction.
+	PropagationSupported
+	// Runs in a session without a transaction. Fails with errorType.TransactionExistsErr if the context has one.
+	PropagationNever
+)
+
+// TransactionOptions represents options of Transaction.
+type TransactionOptions struct {
+	// The options of the session the transaction runs in.
+	Session *options.SessionOptions
+
+	// The options of the transaction, such as its read and write concerns.
+	Transaction *options.TransactionOptions
+
+	// The total time of the transaction, including the retries of the callback and the commit.
+	// When it runs out, the transaction is aborted and a timeout error is returned. The default value is no limit.
+	Timeout *time.Duration
+
+	// How the transaction runs when the context already has one. The default value is PropagationRequired.
+	Propagation *Propagation
+}
+
+func NewTransactionOptions() *TransactionOptions {
+	return &TransactionOptions{}
+}
+
+func (o *TransactionOptions) SetSession(sessionOpt *options.SessionOptions) *TransactionOptions {
+	o.Session = sessionOpt
+	return o
+}
+
+func (o *TransactionOptions) SetTransaction(trxOpt *options.TransactionOptions) *TransactionOptions {
+	o.Transaction = trxOpt
+	return o
+}
+
+func (o *TransactionOptions) SetTimeout(timeout time.Duration) *TransactionOptions {
+	o.Timeout = &timeout
+	return o
+}
+
+func (o *TransactionOptions) SetPropagation(propagation Propagation) *TransactionOptions {
+	o.Propagation = &propagation
+	return o
+}
+
+func mergeTransactionOptions(opts ...*TransactionOptions) *TransactionOptions {
+	merged := NewTransactionOptions().
+		SetSession(options.Session()).
+		SetTransaction(options.Transaction()).
+		SetPropagation(PropagationRequired)
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.Session != nil {
+			merged.Session = opt.Session
+		}
+		if opt.Transaction != nil {
+			merged.Transaction = opt.Transaction
+		}
+		if opt.Timeout != nil {
+			merged.Timeout = opt.Timeout
+		}
+		if opt.Propagation != nil {
+			merged.Propagation = opt.Propagation
+		}
+	}
+	return merged
+}
@@ -0,0 +1,184 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_Transaction(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("typed result", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(
+			mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch, bson.D{{Key: "account_id", Value: 1}, {Key: "limit", Value: 10}}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		result, err := Transaction(context.Background(), &Client{Client: t.Client}, func(sessCtx mongo.SessionContext) (*account, error) {
+			var data account
+			if err := col.FindOneWithTrx(logger, &data, bson.M{"account_id": 1}, &sessCtx); err != nil {
+				return nil, err
+			}
+			return &data, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &account{AccountId: 1, Limit: 10}, result)
+	})
+
+	mt.Run("error of fn", func(t *mtest.T) {
+		errInsufficient := errors.New("insufficient limit")
+		t.AddMockResponses(mtest.CreateSuccessResponse())
+
+		result, err := Transaction(context.Background(), &Client{Client: t.Client}, func(sessCtx mongo.SessionContext) (int, error) {
+			return 0, errInsufficient
+		})
+		assert.Equal(t, errInsufficient, err)
+		assert.Equal(t, 0, result)
+	})
+
+	mt.Run("classified error of fn", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateCursorResponse(0, t.DB.Name()+"."+t.Coll.Name(), mtest.FirstBatch), mtest.CreateSuccessResponse())
+
+		_, err := Transaction(context.Background(), &Client{Client: t.Client}, func(sessCtx mongo.SessionContext) (account, error) {
+			var data account
+			err := col.FindOneWithTrx(logger, &data, bson.M{"account_id": 1}, &sessCtx)
+			return data, err
+		})
+		assert.True(t, errorType.IsNotFoundErr(err))
+	})
+
+	mt.Run("unknown commit result", func(t *mtest.T) {
+		commitErr := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 50, Name: "MaxTimeMSExpired", Message: "commit", Labels: []string{"UnknownTransactionCommitResult"}})
+		t.AddMockResponses(mtest.CreateSuccessResponse(), commitErr)
+
+		_, err := Transaction(context.Background(), &Client{Client: t.Client}, func(sessCtx mongo.SessionContext) (bool, error) {
+			return true, t.Coll.Database().RunCommand(sessCtx, bson.D{{Key: "ping", Value: 1}}).Err()
+		})
+		assert.True(t, errorType.IsUnknownCommitResultErr(err))
+		assert.False(t, errorType.IsTransientTransactionErr(err))
+		assert.False(t, errorType.IsRetryable(err))
+		assert.False(t, errorType.IsTransient(err))
+		assert.True(t, errorType.IsCommitRetryable(err))
+	})
+
+	mt.Run("timeout", func(t *mtest.T) {
+		_, err := Transaction(context.Background(), &Client{Client: t.Client}, func(sessCtx mongo.SessionContext) (bool, error) {
+			<-sessCtx.Done()
+			return false, sessCtx.Err()
+		}, NewTransactionOptions().SetTimeout(10*time.Millisecond))
+		assert.True(t, errorType.IsTimeoutError(err))
+	})
+}
+
+func Test_TransactionPropagation(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+	inserted := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1})
+
+	nested := func(t *mtest.T, propagation Propagation) error {
+		client := &Client{Client: t.Client}
+		col := NewCollection[account](client, t.DB.Name(), t.Coll.Name())
+		_, err := Transaction(context.Background(), client, func(sessCtx mongo.SessionContext) (bool, error) {
+			if _, err := col.InSession(sessCtx).InsertOne(logger, bson.M{"account_id": 1}); err != nil {
+				return false, err
+			}
+			return Transaction(sessCtx, client, func(innerCtx mongo.SessionContext) (bool, error) {
+				_, err := col.InSession(innerCtx).InsertOne(logger, bson.M{"account_id": 2})
+				return true, err
+			}, NewTransactionOptions().SetPropagation(propagation))
+		})
+		return err
+	}
+
+	mt.Run("required joins the outer transaction", func(t *mtest.T) {
+		t.AddMockResponses(inserted, inserted, mtest.CreateSuccessResponse())
+		assert.NoError(t, nested(t, PropagationRequired))
+
+		outer, inner := t.GetStartedEvent().Command, t.GetStartedEvent().Command
+		assert.Equal(t, outer.Lookup("lsid"), inner.Lookup("lsid"))
+		assert.Equal(t, outer.Lookup("txnNumber"), inner.Lookup("txnNumber"))
+		assert.Equal(t, "commitTransaction", t.GetStartedEvent().CommandName)
+		assert.Nil(t, t.GetStartedEvent())
+	})
+
+	mt.Run("requires new commits on its own", func(t *mtest.T) {
+		t.AddMockResponses(inserted, inserted, mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		assert.NoError(t, nested(t, PropagationRequiresNew))
+
+		outer, inner := t.GetStartedEvent().Command, t.GetStartedEvent().Command
+		assert.NotEqual(t, outer.Lookup("lsid"), inner.Lookup("lsid"))
+		assert.True(t, inner.Lookup("startTransaction").Boolean())
+		innerCommit, outerCommit := t.GetStartedEvent(), t.GetStartedEvent()
+		assert.Equal(t, "commitTransaction", innerCommit.CommandName)
+		assert.Equal(t, inner.Lookup("lsid"), innerCommit.Command.Lookup("lsid"))
+		assert.Equal(t, "commitTransaction", outerCommit.CommandName)
+		assert.Equal(t, outer.Lookup("lsid"), outerCommit.Command.Lookup("lsid"))
+	})
+
+	mt.Run("supported joins the outer transaction", func(t *mtest.T) {
+		t.AddMockResponses(inserted, inserted, mtest.CreateSuccessResponse())
+		assert.NoError(t, nested(t, PropagationSupported))
+
+		outer, inner := t.GetStartedEvent().Command, t.GetStartedEvent().Command
+		assert.Equal(t, outer.Lookup("txnNumber"), inner.Lookup("txnNumber"))
+	})
+
+	mt.Run("supported runs without a transaction", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(inserted)
+
+		_, err := Transaction(context.Background(), &Client{Client: t.Client}, func(sessCtx mongo.SessionContext) (bool, error) {
+			_, err := col.InSession(sessCtx).InsertOne(logger, bson.M{"account_id": 1})
+			return true, err
+		}, NewTransactionOptions().SetPropagation(PropagationSupported))
+		assert.NoError(t, err)
+
+		_, err = t.GetStartedEvent().Command.LookupErr("startTransaction")
+		assert.Error(t, err)
+		assert.Nil(t, t.GetStartedEvent())
+	})
+
+	mt.Run("failed inner transaction aborts the outer one", func(t *mtest.T) {
+		client := &Client{Client: t.Client}
+		col := NewCollection[account](client, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(inserted, mtest.CreateSuccessResponse())
+		innerErr := errors.New("inner")
+
+		_, err := Transaction(context.Background(), client, func(sessCtx mongo.SessionContext) (bool, error) {
+			if _, err := col.InSession(sessCtx).InsertOne(logger, bson.M{"account_id": 1}); err != nil {
+				return false, err
+			}
+			// the error of the inner transaction is swallowed
+			_, _ = Transaction(sessCtx, client, func(innerCtx mongo.SessionContext) (bool, error) {
+				return false, innerErr
+			})
+			return true, nil
+		})
+		assert.True(t, errors.Is(err, errorType.RollbackOnlyErr))
+		assert.Contains(t, err.Error(), innerErr.Error())
+
+		assert.Equal(t, "insert", t.GetStartedEvent().CommandName)
+		assert.Equal(t, "abortTransaction", t.GetStartedEvent().CommandName)
+		assert.Nil(t, t.GetStartedEvent())
+	})
+
+	mt.Run("never fails in a transaction", func(t *mtest.T) {
+		t.AddMockResponses(inserted)
+		err := nested(t, PropagationNever)
+		assert.True(t, errors.Is(err, errorType.TransactionExistsErr))
+	})
+}
@@ -0,0 +1,141 @@
+package wrapper
+
+import (
+	"context"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type UpsertOutcome int
+
+const (
+	UpsertUnchanged UpsertOutcome = iota
+	UpsertInserted
+	UpsertUpdated
+)
+
+func (o UpsertOutcome) String() string {
+	switch o {
+	case UpsertInserted:
+		return "inserted"
+	case UpsertUpdated:
+		return "updated"
+	}
+	return "unchanged"
+}
+
+// UpsertResult tells whether an upsert inserted, updated or left the document as it was.
+// UpsertedID is set only when the document was inserted. On an audited collection, a matched document is always updated.
+type UpsertResult struct {
+	Outcome    UpsertOutcome
+	UpsertedID interface{}
+}
+
+// UpsertOne updates the document matching the filter, or inserts it if nothing matches.
+func (col *Collection[T]) UpsertOne(logger Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*UpsertResult, error) {
+	ctx, ctxCancel := col.newContext(logger)
+	defer ctxCancel()
+	result, err := col.upsertOne(logger, ctx, filter, update, opts...)
+	return result, col.withWriteOperation(err, "UpsertOne")
+}
+
+// UpsertByKey sets the fields of the document on the document with the same key fields, or inserts it.
+// The key defaults to _id. _id and the fields managed by the collection options are only written on insert or by the options.
+func (col *Collection[T]) UpsertByKey(logger Logger, document T, keyFields ...string) (*UpsertResult, error) {
+	ctx, ctxCancel := col.newContext(logger)
+	defer ctxCancel()
+	result, err := col.upsertByKey(logger, ctx, document, keyFields...)
+	return result, col.withWriteOperation(err, "UpsertByKey")
+}
+
+func (col *Collection[T]) UpsertOneWithTrx(logger Logger, filter interface{}, update interface{}, sessCtx *mongo.SessionContext, opts ...*options.UpdateOptions) (*UpsertResult, error) {
+	result, err := col.upsertOne(logger, *sessCtx, filter, update, opts...)
+	return result, col.withWriteOperation(err, "UpsertOne")
+}
+
+func (col *Collection[T]) UpsertByKeyWithTrx(logger Logger, document T, sessCtx *mongo.SessionContext, keyFields ...string) (*UpsertResult, error) {
+	result, err := col.upsertByKey(logger, *sessCtx, document, keyFields...)
+	return result, col.withWriteOperation(err, "UpsertByKey")
+}
+
+func (col *Collection[T]) upsertOne(logger Logger, ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*UpsertResult, error) {
+	opts = append(opts, options.Update().SetUpsert(true))
+	updateResult, err := col.updateOne(logger, ctx, filter, update, opts...)
+	if err != nil {
+		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil)
+	}
+	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
+		return nil, col.notMatchedError(ctx, filter, update, nil, col.filtersVersion(filter))
+	}
+	return upsertResultOf(updateResult), nil
+}
+
+func (col *Collection[T]) upsertByKey(logger Logger, ctx context.Context, document T, keyFields ...string) (*UpsertResult, error) {
+	filter, update, err := col.upsertByKeyQuery(document, keyFields...)
+	if err != nil {
+		return nil, err
+	}
+	return col.upsertOne(logger, ctx, filter, update)
+}
+
+// upsertByKeyQuery returns the filter on the key fields and an update that $sets the other fields of the document.
+func (col *Collection[T]) upsertByKeyQuery(document T, keyFields ...string) (bson.D, bson.D, error) {
+	if len(keyFields) == 0 {
+		keyFields = []string{"_id"}
+	}
+	doc, err := toDocument(col.registry, document)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter := bson.D{}
+	for _, field := range keyFields {
+		value, ok := lookup(doc, field)
+		if !ok {
+			return nil, nil, errors.Wrap(errorType.UpsertKeyMissingErr, field)
+		}
+		filter = append(filter, bson.E{Key: field, Value: value})
+	}
+
+	managed := map[string]bool{col.createdAtField: true, col.updatedAtField: true, col.versionField: true, col.softDeleteField: true}
+	set, setOnInsert := bson.D{}, bson.D{}
+	for _, element := range doc {
+		switch {
+		case element.Key == "_id":
+			if _, isKey := lookup(filter, "_id"); !isKey {
+				setOnInsert = append(setOnInsert, element)
+			}
+		case managed[element.Key]:
+		default:
+			if _, isKey := lookup(filter, element.Key); !isKey {
+				set = append(set, element)
+			}
+		}
+	}
+
+	update := bson.D{}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(setOnInsert) > 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: setOnInsert})
+	}
+	if len(update) == 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: filter})
+	}
+	return filter, update, nil
+}
+
+func upsertResultOf(result *mongo.UpdateResult) *UpsertResult {
+	switch {
+	case result.UpsertedCount > 0:
+		return &UpsertResult{Outcome: UpsertInserted, UpsertedID: result.UpsertedID}
+	case result.ModifiedCount > 0:
+		return &UpsertResult{Outcome: UpsertUpdated}
+	}
+	return &UpsertResult{Outcome: UpsertUnchanged}
+}
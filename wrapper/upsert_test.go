@@ -0,0 +1,75 @@
+package wrapper
+
+import (
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func Test_upsertByKeyQuery(t *testing.T) {
+	col := &Collection[account]{}
+	col.applyOptions(NewCollectionOptions().SetTimestamps(true))
+
+	filter, update, err := col.upsertByKeyQuery(account{AccountId: 1, Limit: 10}, "account_id")
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "account_id", Value: int32(1)}}, filter)
+	set, _ := lookup(update, "$set")
+	assert.Equal(t, bson.D{{Key: "limit", Value: int32(10)}, {Key: "products", Value: nil}}, set)
+
+	_, _, err = col.upsertByKeyQuery(account{AccountId: 1}, "name")
+	assert.True(t, errors.Is(err, errorType.UpsertKeyMissingErr))
+}
+
+func Test_Upsert(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+	upserted := bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: 7}}}}
+
+	mt.Run("update one with upsert is not not found", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}, upserted))
+
+		result, err := col.UpdateOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 10}}, options.Update().SetUpsert(true))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.UpsertedCount)
+	})
+
+	mt.Run("inserted", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}, upserted))
+
+		result, err := col.UpsertByKey(logger, account{AccountId: 1, Limit: 10}, "account_id")
+		assert.NoError(t, err)
+		assert.Equal(t, UpsertInserted, result.Outcome)
+		assert.Equal(t, int32(7), result.UpsertedID)
+
+		update := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.True(t, update.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("updated", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		result, err := col.UpsertOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 10}})
+		assert.NoError(t, err)
+		assert.Equal(t, UpsertUpdated, result.Outcome)
+		assert.Nil(t, result.UpsertedID)
+	})
+
+	mt.Run("unchanged", func(t *mtest.T) {
+		col := NewCollection[account](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name())
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}))
+
+		result, err := col.UpsertOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"limit": 10}})
+		assert.NoError(t, err)
+		assert.Equal(t, UpsertUnchanged, result.Outcome)
+	})
+}
@@ -0,0 +1,146 @@
+package wrapper
+
+import (
+	"context"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultVersionField = "version"
+
+	versionTag = "version"
+)
+
+func (col *Collection[T]) versioningEnabled() bool {
+	return col.versionField != ""
+}
+
+// lockReplacement adds the version of the replacement to the filter and increments the version of the replacement.
+// Documents without a version are treated as version 0.
+func (col *Collection[T]) lockReplacement(filter, replacement interface{}) (interface{}, interface{}) {
+	if !col.versioningEnabled() {
+		return filter, replacement
+	}
+	doc, err := toDocument(col.registry, replacement)
+	if err != nil {
+		return filter, replacement
+	}
+	current, _ := lookup(doc, col.versionField)
+	expected, next, ok := nextVersion(current)
+	if !ok {
+		return filter, replacement
+	}
+
+	condition := bson.M{col.versionField: expected}
+	if current == nil || expected == int32(0) || expected == int64(0) || expected == float64(0) {
+		condition = bson.M{col.versionField: bson.M{"$in": bson.A{0, nil}}}
+	}
+	if filter == nil {
+		return condition, setField(doc, col.versionField, next)
+	}
+	return bson.D{{Key: "$and", Value: bson.A{filter, condition}}}, setField(doc, col.versionField, next)
+}
+
+// incrementVersion adds $inc of the version to an update unless the update already writes the version.
+func (col *Collection[T]) incrementVersion(update interface{}) interface{} {
+	if !col.versioningEnabled() {
+		return update
+	}
+	doc, pipeline, ok := toUpdate(update)
+	if !ok || updatedFields(doc, pipeline)[col.versionField] {
+		return update
+	}
+	if pipeline != nil {
+		increment := bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + col.versionField, 0}}}, 1}}}
+		return append(pipeline, bson.D{{Key: "$set", Value: bson.D{{Key: col.versionField, Value: increment}}}})
+	}
+	return addOperand(doc, "$inc", bson.E{Key: col.versionField, Value: 1})
+}
+
+func (col *Collection[T]) lockModels(models []mongo.WriteModel) []mongo.WriteModel {
+	if !col.versioningEnabled() {
+		return models
+	}
+	locked := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		switch m := model.(type) {
+		case *mongo.UpdateOneModel:
+			copied := *m
+			copied.Update = col.incrementVersion(m.Update)
+			locked[i] = &copied
+		case *mongo.UpdateManyModel:
+			copied := *m
+			copied.Update = col.incrementVersion(m.Update)
+			locked[i] = &copied
+		case *mongo.ReplaceOneModel:
+			copied := *m
+			copied.Filter, copied.Replacement = col.lockReplacement(m.Filter, m.Replacement)
+			locked[i] = &copied
+		default:
+			locked[i] = model
+		}
+	}
+	return locked
+}
+
+// filtersVersion reports whether the filter of an update compares the version.
+func (col *Collection[T]) filtersVersion(filter interface{}) bool {
+	if !col.versioningEnabled() || filter == nil {
+		return false
+	}
+	doc, err := toDocument(col.registry, filter)
+	if err != nil {
+		return false
+	}
+	_, ok := lookup(doc, col.versionField)
+	return ok
+}
+
+// notMatchedError returns versionConflictError if the document exists without the expected version, and notFoundError otherwise.
+func (col *Collection[T]) notMatchedError(ctx context.Context, filter, update, doc interface{}, versionChecked bool) error {
+	if versionChecked && col.versioningEnabled() {
+		count, err := col.Collection.CountDocuments(ctx, col.scopeFilter(col.withoutVersion(filter)), options.Count().SetLimit(1))
+		if err != nil {
+			return errorType.ParseAndReturnDBError(err, col.Name(), filter, update, doc)
+		}
+		if count > 0 {
+			return errorType.VersionConflictError(col.Name(), filter, update, doc)
+		}
+	}
+	return errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, update, doc)
+}
+
+func (col *Collection[T]) withoutVersion(filter interface{}) interface{} {
+	if filter == nil {
+		return bson.D{}
+	}
+	doc, err := toDocument(col.registry, filter)
+	if err != nil {
+		return filter
+	}
+	result := bson.D{}
+	for _, element := range doc {
+		if element.Key != col.versionField {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+func nextVersion(current interface{}) (interface{}, interface{}, bool) {
+	switch version := current.(type) {
+	case nil:
+		return int32(0), int32(1), true
+	case int32:
+		return version, version + 1, true
+	case int64:
+		return version, version + 1, true
+	case float64:
+		return version, version + 1, true
+	}
+	return nil, nil, false
+}
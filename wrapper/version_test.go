@@ -0,0 +1,97 @@
+package wrapper
+
+import (
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type versionedAccount struct {
+	AccountId int   `bson:"account_id"`
+	Rev       int64 `bson:"rev" mongo:"version"`
+}
+
+func newVersionedCollection(t *mtest.T) *Collection[versionedAccount] {
+	return NewCollection[versionedAccount](&Client{Client: t.Client}, t.DB.Name(), t.Coll.Name(), NewCollectionOptions().SetOptimisticLock(true))
+}
+
+func Test_lockReplacement(t *testing.T) {
+	col := &Collection[versionedAccount]{}
+	col.applyOptions(NewCollectionOptions().SetOptimisticLock(true))
+	assert.Equal(t, "rev", col.versionField)
+
+	filter, replacement := col.lockReplacement(bson.M{"account_id": 1}, versionedAccount{AccountId: 1, Rev: 3})
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{bson.M{"account_id": 1}, bson.M{"rev": int64(3)}}}}, filter)
+	rev, _ := lookup(replacement.(bson.D), "rev")
+	assert.Equal(t, int64(4), rev)
+
+	filter, replacement = col.lockReplacement(nil, bson.M{"account_id": 1})
+	assert.Equal(t, bson.M{"rev": bson.M{"$in": bson.A{0, nil}}}, filter)
+	rev, _ = lookup(replacement.(bson.D), "rev")
+	assert.Equal(t, int32(1), rev)
+}
+
+func Test_incrementVersion(t *testing.T) {
+	col := &Collection[versionedAccount]{versionField: "rev"}
+
+	update := col.incrementVersion(bson.M{"$set": bson.M{"account_id": 2}}).(bson.D)
+	inc, _ := lookup(update, "$inc")
+	assert.Equal(t, bson.D{{Key: "rev", Value: 1}}, inc)
+
+	written := bson.M{"$set": bson.M{"rev": 10}}
+	assert.Equal(t, written, col.incrementVersion(written))
+
+	assert.True(t, col.filtersVersion(bson.M{"account_id": 1, "rev": 3}))
+	assert.False(t, col.filtersVersion(bson.M{"account_id": 1}))
+}
+
+func Test_OptimisticLock(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	logger := &myLogger{logrus.New()}
+
+	mt.Run("replace success", func(t *mtest.T) {
+		col := newVersionedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		_, err := col.ReplaceOne(logger, bson.M{"account_id": 1}, versionedAccount{AccountId: 1, Rev: 1})
+		assert.NoError(t, err)
+
+		update := t.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, int64(2), update.Lookup("u", "rev").Int64())
+	})
+
+	mt.Run("replace conflict", func(t *mtest.T) {
+		col := newVersionedCollection(t)
+		t.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "n", Value: 1}}),
+		)
+
+		_, err := col.ReplaceOne(logger, bson.M{"account_id": 1}, versionedAccount{AccountId: 1, Rev: 1})
+		assert.True(t, errorType.IsVersionConflictErr(err))
+	})
+
+	mt.Run("replace not found", func(t *mtest.T) {
+		col := newVersionedCollection(t)
+		t.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+		)
+
+		_, err := col.ReplaceOne(logger, bson.M{"account_id": 1}, versionedAccount{AccountId: 1, Rev: 1})
+		assert.True(t, errorType.IsNotFoundErr(err))
+	})
+
+	mt.Run("update without version in filter is not found", func(t *mtest.T) {
+		col := newVersionedCollection(t)
+		t.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		_, err := col.UpdateOne(logger, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"account_id": 2}})
+		assert.True(t, errorType.IsNotFoundErr(err))
+	})
+}
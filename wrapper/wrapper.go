@@ -5,210 +5,251 @@ import (
 
 	"github.com/kjh03160/go-mongo/errorType"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Collection[T any] struct {
 	*mongo.Collection
+	softDeleteField string
+	deletedScope    deletedScope
+	createdAtField  string
+	updatedAtField  string
+	versionField    string
+	auditCollection *mongo.Collection
+	onAuditError    func(ctx context.Context, entries []AuditEntry, err error)
+	sessCtx         mongo.SessionContext
+	explainer       *explainer
+	queryStats      *QueryStats
+	sampler         *SlowQuerySampler
+	criticalFactor  float64
+	measureCommands bool
+	registry        *bsoncodec.Registry
+}
+
+func NewCollection[T any](mongoClient *Client, databaseName, collectionName string, opts ...*CollectionOptions) *Collection[T] {
+	collection := mongoClient.GetCollection(databaseName, collectionName)
+	col := &Collection[T]{Collection: collection, registry: mongoClient.registry}
+	col.applyOptions(mergeCollectionOptions(opts...))
+	return col
 }
 
-func NewCollection[T any](mongoClient *Client, databaseName, collectionName string) *Collection[T] {
-	collection := mongoClient.GetCollection(databaseName, collectionName)
-	return &Collection[T]{Collection: collection}
+// withOperation is errorType.WithOperation that also redacts the sensitive fields of T in the error.
+func (col *Collection[T]) withOperation(err error, operation string) error {
+	return errorType.WithOperation(errorType.WithDocumentType(err, documentTypeOf[T]()), operation)
+}
+
+// withWriteOperation is errorType.WithWriteOperation that also redacts the sensitive fields of T in the error.
+func (col *Collection[T]) withWriteOperation(err error, operation string) error {
+	return errorType.WithWriteOperation(errorType.WithDocumentType(err, documentTypeOf[T]()), operation)
 }
 
 func (col *Collection[T]) FindAll(logger Logger, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	cursor, err := col.findAll(logger, ctx, filter, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindAll")
 	}
-	resultSlice, err := DecodeCursor[T](cursor)
+	resultSlice, err := decodeAll[T](cursor)
 	if err != nil {
-		return nil, errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return nil, col.withOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindAll")
 	}
 	return resultSlice, nil
 }
 
 func (col *Collection[T]) FindOne(logger Logger, data, filter interface{}, opts ...*options.FindOneOptions) error {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	singleResult := col.findOne(logger, ctx, filter, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
 		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+			return col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOne")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return col.withOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOne")
 	}
 	return nil
 }
 
 func (col *Collection[T]) FindOneAndModify(logger Logger, data, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) error {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
-	singleResult := col.findOneAndModify(logger, ctx, filter, update, opts...)
+	singleResult, auditErr := col.findOneAndModify(logger, ctx, filter, update, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
-		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		if err == mongo.ErrNoDocuments {
+			return col.withWriteOperation(col.notMatchedError(ctx, filter, nil, nil, col.filtersVersion(filter)), "FindOneAndModify")
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOneAndModify")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return col.withWriteOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOneAndModify")
 	}
-	return nil
+	return col.withWriteOperation(auditErr, "FindOneAndModify")
 }
 
 func (col *Collection[T]) FindOneAndReplace(logger Logger, data, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) error {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
-	singleResult := col.findOneAndReplace(logger, ctx, filter, replacement, opts...)
+	singleResult, auditErr := col.findOneAndReplace(logger, ctx, filter, replacement, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
-		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		if err == mongo.ErrNoDocuments {
+			return col.withWriteOperation(col.notMatchedError(ctx, filter, nil, replacement, true), "FindOneAndReplace")
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOneAndReplace")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return col.withWriteOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOneAndReplace")
 	}
-	return nil
+	return col.withWriteOperation(auditErr, "FindOneAndReplace")
 }
 
 func (col *Collection[T]) FindOneAndDelete(logger Logger, data, filter interface{}, opts ...*options.FindOneAndDeleteOptions) error {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
-	singleResult := col.findOneAndDelete(logger, ctx, filter, opts...)
+	singleResult, auditErr := col.findOneAndDelete(logger, ctx, filter, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
 		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+			return col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOneAndDelete")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return col.withWriteOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOneAndDelete")
 	}
-	return nil
+	return col.withWriteOperation(auditErr, "FindOneAndDelete")
 }
 
 func (col *Collection[T]) InsertOne(logger Logger, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	insertOneResult, err := col.insertOne(logger, ctx, document, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, document)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, document), "InsertOne")
 	}
 	return insertOneResult.InsertedID, nil
 }
 
 func (col *Collection[T]) InsertMany(logger Logger, documents []interface{}, opts ...*options.InsertManyOptions) (interface{}, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	insertOneResult, err := col.insertMany(logger, ctx, documents, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, documents)
+		err = col.withWriteOperation(errorType.ParseAndReturnBulkError(err, col.Name(), nil, documents), "InsertMany")
+		if insertOneResult == nil || !errorType.IsBulkErr(err) {
+			return nil, err
+		}
+		return insertOneResult.InsertedIDs, err
 	}
 	return insertOneResult.InsertedIDs, nil
 }
 
 func (col *Collection[T]) UpdateOne(logger Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	updateResult, err := col.updateOne(logger, ctx, filter, update, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil), "UpdateOne")
 	}
-	if updateResult.MatchedCount == 0 {
-		return updateResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, update, nil)
+	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
+		return updateResult, col.withWriteOperation(col.notMatchedError(ctx, filter, update, nil, col.filtersVersion(filter)), "UpdateOne")
 	}
 	return updateResult, nil
 }
 
 func (col *Collection[T]) UpdateMany(logger Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	updateResult, err := col.updateMany(logger, ctx, filter, update, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil), "UpdateMany")
 	}
-	if updateResult.MatchedCount == 0 {
-		return updateResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, update, nil)
+	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
+		return updateResult, col.withWriteOperation(col.notMatchedError(ctx, filter, update, nil, col.filtersVersion(filter)), "UpdateMany")
 	}
 	return updateResult, nil
 }
 
 func (col *Collection[T]) ReplaceOne(logger Logger, filter interface{}, document interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	result, err := col.replaceOne(logger, ctx, filter, document, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, document)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, document), "ReplaceOne")
 	}
-	if result.MatchedCount == 0 {
-		return result, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, document)
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return result, col.withWriteOperation(col.notMatchedError(ctx, filter, nil, document, true), "ReplaceOne")
 	}
 	return result, nil
 }
 
 func (col *Collection[T]) DeleteOne(logger Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	deleteResult, err := col.deleteOne(logger, ctx, filter, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "DeleteOne")
 	}
 	if deleteResult.DeletedCount == 0 {
-		return deleteResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil)
+		return deleteResult, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil), "DeleteOne")
 	}
 	return deleteResult, nil
 }
 
 func (col *Collection[T]) DeleteMany(logger Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	deleteResult, err := col.deleteMany(logger, ctx, filter, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "DeleteMany")
 	}
 	if deleteResult.DeletedCount == 0 {
-		return deleteResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil)
+		return deleteResult, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil), "DeleteMany")
 	}
 	return deleteResult, nil
 }
 
 func (col *Collection[T]) CountDocuments(logger Logger, filter interface{}, opts ...*options.CountOptions) (int, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	count, err := col.countDocuments(logger, ctx, filter, opts...)
 	if err != nil {
-		return 0, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return 0, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "CountDocuments")
 	}
 	return int(count), nil
 }
 
 func (col *Collection[T]) EstimatedDocumentCount(logger Logger, opts ...*options.EstimatedDocumentCountOptions) (int, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	count, err := col.estimatedDocumentCount(logger, ctx, opts...)
 	if err != nil {
-		return 0, errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, nil)
+		return 0, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, nil), "EstimatedDocumentCount")
 	}
 	return int(count), nil
 }
 
 func (col *Collection[T]) BulkWrite(logger Logger, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	bulkWriteResult, err := col.bulkWrite(logger, ctx, models, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), nil, models, nil)
+		err = col.withWriteOperation(errorType.ParseAndReturnBulkError(err, col.Name(), models, nil), "BulkWrite")
+		if !errorType.IsBulkErr(err) {
+			return nil, err
+		}
+		return bulkWriteResult, err
 	}
 	return bulkWriteResult, nil
 }
 
 func (col *Collection[T]) Aggregate(logger Logger, pipeline interface{}, opts ...*options.AggregateOptions) ([]T, error) {
-	ctx, ctxCancel := context.WithTimeout(context.Background(), logger.GetTimeoutDuration())
+	ctx, ctxCancel := col.newContext(logger)
 	defer ctxCancel()
 	cursor, err := col.aggregate(logger, ctx, pipeline, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), pipeline, nil, nil)
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), pipeline, nil, nil), "Aggregate")
 	}
-	resultSlice, err := DecodeCursor[T](cursor)
+	resultSlice, err := decodeAll[T](cursor)
 	if err != nil {
-		return nil, errorType.DecodeError(col.Name(), pipeline, nil, nil, err)
+		return nil, col.withOperation(errorType.DecodeError(col.Name(), pipeline, nil, nil, err), "Aggregate")
 	}
 	return resultSlice, nil
 }
@@ -216,11 +257,11 @@ func (col *Collection[T]) Aggregate(logger Logger, pipeline interface{}, opts ..
 func (col *Collection[T]) FindAllWithTrx(logger Logger, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.FindOptions) ([]T, error) {
 	cursor, err := col.findAll(logger, *sessCtx, filter, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindAll")
 	}
-	resultSlice, err := DecodeCursor[T](cursor)
+	resultSlice, err := decodeAll[T](cursor)
 	if err != nil {
-		return nil, errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return nil, col.withOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindAll")
 	}
 	return resultSlice, nil
 }
@@ -229,50 +270,56 @@ func (col *Collection[T]) FindOneWithTrx(logger Logger, data, filter interface{}
 	singleResult := col.findOne(logger, *sessCtx, filter, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
 		if err == mongo.ErrNoDocuments || errors.Unwrap(err) == context.DeadlineExceeded {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+			return col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOne")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return col.withOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOne")
 	}
 	return nil
 }
 
 func (col *Collection[T]) FindOneAndModifyWithTrx(logger Logger, data, filter interface{}, update interface{}, sessCtx *mongo.SessionContext, opts ...*options.FindOneAndUpdateOptions) error {
-	singleResult := col.findOneAndModify(logger, *sessCtx, filter, update, opts...)
+	singleResult, auditErr := col.findOneAndModify(logger, *sessCtx, filter, update, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
-		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		if err == mongo.ErrNoDocuments {
+			return col.withWriteOperation(col.notMatchedError(*sessCtx, filter, nil, nil, col.filtersVersion(filter)), "FindOneAndModify")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOneAndModify")
+		}
+		return col.withWriteOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOneAndModify")
 	}
-	return nil
+	return col.withWriteOperation(auditErr, "FindOneAndModify")
 }
 
 func (col *Collection[T]) FindOneAndReplaceWithTrx(logger Logger, data, filter interface{}, replacement interface{}, sessCtx *mongo.SessionContext, opts ...*options.FindOneAndReplaceOptions) error {
-	singleResult := col.findOneAndReplace(logger, *sessCtx, filter, replacement, opts...)
+	singleResult, auditErr := col.findOneAndReplace(logger, *sessCtx, filter, replacement, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
-		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		if err == mongo.ErrNoDocuments {
+			return col.withWriteOperation(col.notMatchedError(*sessCtx, filter, nil, replacement, true), "FindOneAndReplace")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOneAndReplace")
+		}
+		return col.withWriteOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOneAndReplace")
 	}
-	return nil
+	return col.withWriteOperation(auditErr, "FindOneAndReplace")
 }
 
 func (col *Collection[T]) FindOneAndDeleteWithTrx(logger Logger, data, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.FindOneAndDeleteOptions) error {
-	singleResult := col.findOneAndDelete(logger, *sessCtx, filter, opts...)
+	singleResult, auditErr := col.findOneAndDelete(logger, *sessCtx, filter, opts...)
 	if err := EvaluateAndDecodeSingleResult(singleResult, data); err != nil {
 		if err == mongo.ErrNoDocuments || errors.Is(err, context.DeadlineExceeded) {
-			return errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+			return col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "FindOneAndDelete")
 		}
-		return errorType.DecodeError(col.Name(), filter, nil, nil, err)
+		return col.withWriteOperation(errorType.DecodeError(col.Name(), filter, nil, nil, err), "FindOneAndDelete")
 	}
-	return nil
+	return col.withWriteOperation(auditErr, "FindOneAndDelete")
 }
 
 func (col *Collection[T]) InsertOneWithTrx(logger Logger, document interface{}, sessCtx *mongo.SessionContext, opts ...*options.InsertOneOptions) (interface{}, error) {
 	insertOneResult, err := col.insertOne(logger, *sessCtx, document, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, document)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, document), "InsertOne")
 	}
 	return insertOneResult.InsertedID, nil
 }
@@ -280,7 +327,11 @@ func (col *Collection[T]) InsertOneWithTrx(logger Logger, document interface{},
 func (col *Collection[T]) InsertManyWithTrx(logger Logger, documents []interface{}, sessCtx *mongo.SessionContext, opts ...*options.InsertManyOptions) (interface{}, error) {
 	insertOneResult, err := col.insertMany(logger, *sessCtx, documents, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, documents)
+		err = col.withWriteOperation(errorType.ParseAndReturnBulkError(err, col.Name(), nil, documents), "InsertMany")
+		if insertOneResult == nil || !errorType.IsBulkErr(err) {
+			return nil, err
+		}
+		return insertOneResult.InsertedIDs, err
 	}
 	return insertOneResult.InsertedIDs, nil
 }
@@ -288,10 +339,10 @@ func (col *Collection[T]) InsertManyWithTrx(logger Logger, documents []interface
 func (col *Collection[T]) UpdateOneWithTrx(logger Logger, filter interface{}, update interface{}, sessCtx *mongo.SessionContext, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
 	updateResult, err := col.updateOne(logger, *sessCtx, filter, update, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil), "UpdateOne")
 	}
-	if updateResult.MatchedCount == 0 {
-		return updateResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, update, nil)
+	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
+		return updateResult, col.withWriteOperation(col.notMatchedError(*sessCtx, filter, update, nil, col.filtersVersion(filter)), "UpdateOne")
 	}
 	return updateResult, nil
 }
@@ -299,10 +350,10 @@ func (col *Collection[T]) UpdateOneWithTrx(logger Logger, filter interface{}, up
 func (col *Collection[T]) UpdateManyWithTrx(logger Logger, filter interface{}, update interface{}, sessCtx *mongo.SessionContext, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
 	updateResult, err := col.updateMany(logger, *sessCtx, filter, update, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, update, nil), "UpdateMany")
 	}
-	if updateResult.MatchedCount == 0 {
-		return updateResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, update, nil)
+	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
+		return updateResult, col.withWriteOperation(col.notMatchedError(*sessCtx, filter, update, nil, col.filtersVersion(filter)), "UpdateMany")
 	}
 	return updateResult, nil
 }
@@ -310,10 +361,10 @@ func (col *Collection[T]) UpdateManyWithTrx(logger Logger, filter interface{}, u
 func (col *Collection[T]) ReplaceOneWithTrx(logger Logger, filter interface{}, document interface{}, sessCtx *mongo.SessionContext, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
 	result, err := col.replaceOne(logger, *sessCtx, filter, document, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, document)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, document), "ReplaceOne")
 	}
-	if result.MatchedCount == 0 {
-		return result, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, document)
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return result, col.withWriteOperation(col.notMatchedError(*sessCtx, filter, nil, document, true), "ReplaceOne")
 	}
 	return result, nil
 }
@@ -321,10 +372,10 @@ func (col *Collection[T]) ReplaceOneWithTrx(logger Logger, filter interface{}, d
 func (col *Collection[T]) DeleteOneWithTrx(logger Logger, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
 	deleteResult, err := col.deleteOne(logger, *sessCtx, filter, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "DeleteOne")
 	}
 	if deleteResult.DeletedCount == 0 {
-		return deleteResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil)
+		return deleteResult, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil), "DeleteOne")
 	}
 	return deleteResult, nil
 }
@@ -332,10 +383,10 @@ func (col *Collection[T]) DeleteOneWithTrx(logger Logger, filter interface{}, se
 func (col *Collection[T]) DeleteManyWithTrx(logger Logger, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
 	deleteResult, err := col.deleteMany(logger, *sessCtx, filter, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "DeleteMany")
 	}
 	if deleteResult.DeletedCount == 0 {
-		return deleteResult, errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil)
+		return deleteResult, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.Name(), filter, nil, nil), "DeleteMany")
 	}
 	return deleteResult, nil
 }
@@ -343,7 +394,7 @@ func (col *Collection[T]) DeleteManyWithTrx(logger Logger, filter interface{}, s
 func (col *Collection[T]) CountDocumentsWithTrx(logger Logger, filter interface{}, sessCtx *mongo.SessionContext, opts ...*options.CountOptions) (int, error) {
 	count, err := col.countDocuments(logger, *sessCtx, filter, opts...)
 	if err != nil {
-		return 0, errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil)
+		return 0, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), filter, nil, nil), "CountDocuments")
 	}
 	return int(count), nil
 }
@@ -351,7 +402,7 @@ func (col *Collection[T]) CountDocumentsWithTrx(logger Logger, filter interface{
 func (col *Collection[T]) EstimatedDocumentCountWithTrx(logger Logger, sessCtx *mongo.SessionContext, opts ...*options.EstimatedDocumentCountOptions) (int, error) {
 	count, err := col.estimatedDocumentCount(logger, *sessCtx, opts...)
 	if err != nil {
-		return 0, errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, nil)
+		return 0, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), nil, nil, nil), "EstimatedDocumentCount")
 	}
 	return int(count), nil
 }
@@ -359,7 +410,11 @@ func (col *Collection[T]) EstimatedDocumentCountWithTrx(logger Logger, sessCtx *
 func (col *Collection[T]) BulkWriteWithTrx(logger Logger, models []mongo.WriteModel, sessCtx *mongo.SessionContext, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
 	bulkWriteResult, err := col.bulkWrite(logger, *sessCtx, models, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), nil, models, nil)
+		err = col.withWriteOperation(errorType.ParseAndReturnBulkError(err, col.Name(), models, nil), "BulkWrite")
+		if !errorType.IsBulkErr(err) {
+			return nil, err
+		}
+		return bulkWriteResult, err
 	}
 	return bulkWriteResult, nil
 }
@@ -367,11 +422,11 @@ func (col *Collection[T]) BulkWriteWithTrx(logger Logger, models []mongo.WriteMo
 func (col *Collection[T]) AggregateWithTrx(logger Logger, pipeline interface{}, sessCtx *mongo.SessionContext, opts ...*options.AggregateOptions) ([]T, error) {
 	cursor, err := col.aggregate(logger, *sessCtx, pipeline, opts...)
 	if err != nil {
-		return nil, errorType.ParseAndReturnDBError(err, col.Name(), pipeline, nil, nil)
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(err, col.Name(), pipeline, nil, nil), "Aggregate")
 	}
-	resultSlice, err := DecodeCursor[T](cursor)
+	resultSlice, err := decodeAll[T](cursor)
 	if err != nil {
-		return nil, errorType.DecodeError(col.Name(), pipeline, nil, nil, err)
+		return nil, col.withOperation(errorType.DecodeError(col.Name(), pipeline, nil, nil, err), "Aggregate")
 	}
 	return resultSlice, nil
 }
@@ -0,0 +1,321 @@
+package wrappertest
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kjh03160/go-mongo/wrapper"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// RecordEnv is the environment variable that makes NewCassette record even if the golden file exists.
+const RecordEnv = "WRAPPERTEST_RECORD"
+
+type Mode int
+
+const (
+	// ModeReplay serves the replies of the golden file without a server.
+	ModeReplay Mode = iota
+	// ModeRecord runs the commands on a server and captures them with their replies.
+	ModeRecord
+)
+
+// Interaction is a command sent to the server and its reply.
+// Failure is the error of a command that got no reply, such as a network error.
+type Interaction struct {
+	Database    string
+	CommandName string
+	Command     bson.Raw
+	Reply       bson.Raw
+	Failure     string
+}
+
+// Cassette records the commands of a client into a golden file, and replays them from it.
+// Commands are replayed in the order they were recorded, so a cassette is used by one test running its queries sequentially.
+// A replayed command must be the recorded one apart from the fields that differ between runs, such as the session.
+//
+//	cassette := wrappertest.NewCassette("testdata/account.json")
+//	client, err := cassette.Connect(options.Client().ApplyURI(uri))
+//	defer cassette.Save()
+//
+// It records if the golden file does not exist or RecordEnv is set, and replays otherwise.
+type Cassette struct {
+	path string
+	mode Mode
+
+	mu           sync.Mutex
+	interactions []Interaction
+	pending      map[int32]int // index of the interaction by the request id while recording
+	next         int           // index of the interaction to replay
+}
+
+func NewCassette(path string) *Cassette {
+	mode := ModeReplay
+	if _, err := os.Stat(path); os.Getenv(RecordEnv) != "" || errors.Is(err, os.ErrNotExist) {
+		mode = ModeRecord
+	}
+	return &Cassette{path: path, mode: mode, pending: map[int32]int{}}
+}
+
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Interactions returns the recorded interactions, or the ones loaded to replay.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Connect returns a client connected to the server with clientOpt and recording, or a client replaying the golden file.
+// The client pings the primary like wrapper.Connect, in both modes.
+func (c *Cassette) Connect(clientOpt *options.ClientOptions) (*wrapper.Client, error) {
+	opt := options.MergeClientOptions(clientOpt)
+	if c.mode == ModeRecord {
+		c.record(opt)
+	} else if err := c.replay(opt); err != nil {
+		return nil, err
+	}
+
+	client, err := mongo.Connect(context.TODO(), opt)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(context.TODO(), readpref.Primary()); err != nil {
+		return nil, err
+	}
+	return &wrapper.Client{Client: client}, nil
+}
+
+// Save writes the recorded interactions to the golden file. It does nothing while replaying.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	golden := make([]goldenInteraction, len(c.interactions))
+	for i, interaction := range c.interactions {
+		g, err := goldenOf(interaction)
+		if err != nil {
+			return err
+		}
+		golden[i] = g
+	}
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// goldenInteraction is an Interaction in the golden file, with the documents in canonical extended JSON
+// so that their types are kept.
+type goldenInteraction struct {
+	Database    string          `json:"database"`
+	CommandName string          `json:"commandName"`
+	Command     json.RawMessage `json:"command"`
+	Reply       json.RawMessage `json:"reply,omitempty"`
+	Failure     string          `json:"failure,omitempty"`
+}
+
+func goldenOf(interaction Interaction) (goldenInteraction, error) {
+	g := goldenInteraction{Database: interaction.Database, CommandName: interaction.CommandName, Failure: interaction.Failure}
+	var err error
+	if g.Command, err = bson.MarshalExtJSON(interaction.Command, true, false); err != nil {
+		return g, err
+	}
+	if interaction.Reply != nil {
+		g.Reply, err = bson.MarshalExtJSON(interaction.Reply, true, false)
+	}
+	return g, err
+}
+
+func (g goldenInteraction) interaction() (Interaction, error) {
+	interaction := Interaction{Database: g.Database, CommandName: g.CommandName, Failure: g.Failure}
+	var err error
+	if interaction.Command, err = rawOf(g.Command); err != nil {
+		return interaction, err
+	}
+	if g.Reply != nil {
+		interaction.Reply, err = rawOf(g.Reply)
+	}
+	return interaction, err
+}
+
+func rawOf(extJSON json.RawMessage) (bson.Raw, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(extJSON, true, &doc); err != nil {
+		return nil, err
+	}
+	return bson.Marshal(doc)
+}
+
+// sensitiveCommands are not recorded. They are not sent by a replaying client either.
+var sensitiveCommands = map[string]bool{
+	"hello": true, "ismaster": true, "isMaster": true, "saslStart": true, "saslContinue": true,
+	"authenticate": true, "getnonce": true, "createUser": true, "updateUser": true,
+}
+
+// record captures the commands with the command monitor, and their replies with a dialer that reads the wire messages.
+// The replies are taken from the wire because the monitor has no reply for failed commands, whose error codes and labels
+// decide the errorType of the error. Compression is disabled and TLS is done by the dialer, so that the messages are readable.
+func (c *Cassette) record(opt *options.ClientOptions) {
+	monitor := opt.Monitor
+	opt.SetMonitor(&event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			c.started(e)
+			if monitor != nil && monitor.Started != nil {
+				monitor.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			c.finished(int32(e.RequestID), e.Reply, "")
+			if monitor != nil && monitor.Succeeded != nil {
+				monitor.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			c.finished(int32(e.RequestID), nil, e.Failure)
+			if monitor != nil && monitor.Failed != nil {
+				monitor.Failed(ctx, e)
+			}
+		},
+	})
+
+	var dialer options.ContextDialer = &net.Dialer{}
+	if opt.Dialer != nil {
+		dialer = opt.Dialer
+	}
+	opt.SetDialer(&recordingDialer{cassette: c, dialer: dialer, tlsConfig: opt.TLSConfig})
+	opt.TLSConfig = nil
+	opt.Compressors = nil
+}
+
+func (c *Cassette) started(e *event.CommandStartedEvent) {
+	if sensitiveCommands[e.CommandName] {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[int32(e.RequestID)] = len(c.interactions)
+	c.interactions = append(c.interactions, Interaction{
+		Database:    e.DatabaseName,
+		CommandName: e.CommandName,
+		Command:     append(bson.Raw(nil), e.Command...),
+	})
+}
+
+// replied keeps the reply to a recorded command, read from the wire.
+func (c *Cassette) replied(responseTo int32, reply bson.Raw) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if index, ok := c.pending[responseTo]; ok {
+		c.interactions[index].Reply = append(bson.Raw(nil), reply...)
+	}
+}
+
+func (c *Cassette) finished(requestID int32, reply bson.Raw, failure string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, ok := c.pending[requestID]
+	if !ok {
+		return
+	}
+	delete(c.pending, requestID)
+	interaction := &c.interactions[index]
+	if interaction.Reply == nil && reply != nil {
+		interaction.Reply = append(bson.Raw(nil), reply...)
+	}
+	if interaction.Reply == nil {
+		interaction.Failure = failure
+	}
+}
+
+type recordingDialer struct {
+	cassette  *Cassette
+	dialer    options.ContextDialer
+	tlsConfig *tls.Config
+}
+
+func (d *recordingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if d.tlsConfig != nil {
+		config := d.tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	return &recordingConn{Conn: conn, cassette: d.cassette}, nil
+}
+
+// recordingConn passes the replies of the server to the cassette as they are read.
+type recordingConn struct {
+	net.Conn
+	cassette *Cassette
+	buf      []byte
+}
+
+func (conn *recordingConn) Read(p []byte) (int, error) {
+	n, err := conn.Conn.Read(p)
+	conn.buf = append(conn.buf, p[:n]...)
+	for {
+		length, _, responseTo, opcode, rem, ok := wiremessage.ReadHeader(conn.buf)
+		if !ok || len(conn.buf) < int(length) {
+			break
+		}
+		if opcode == wiremessage.OpMsg {
+			if reply, ok := msgDocument(rem[:int(length)-16]); ok {
+				conn.cassette.replied(responseTo, reply)
+			}
+		}
+		conn.buf = conn.buf[length:]
+	}
+	return n, err
+}
+
+// msgDocument returns the body document of an OP_MSG after its header.
+func msgDocument(src []byte) (bson.Raw, bool) {
+	_, rem, ok := wiremessage.ReadMsgFlags(src)
+	if !ok {
+		return nil, false
+	}
+	for len(rem) > 0 {
+		var sectionType wiremessage.SectionType
+		if sectionType, rem, ok = wiremessage.ReadMsgSectionType(rem); !ok {
+			return nil, false
+		}
+		if sectionType == wiremessage.SingleDocument {
+			doc, _, ok := wiremessage.ReadMsgSectionSingleDocument(rem)
+			return bson.Raw(doc), ok
+		}
+		if _, _, rem, ok = wiremessage.ReadMsgSectionRawDocumentSequence(rem); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
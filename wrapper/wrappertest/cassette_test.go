@@ -0,0 +1,234 @@
+package wrappertest
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/kjh03160/go-mongo/wrapper"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+func replyMessage(t *testing.T, responseTo int32, reply bson.D) []byte {
+	doc, err := bson.Marshal(reply)
+	assert.NoError(t, err)
+	index, wm := wiremessage.AppendHeaderStart(nil, wiremessage.NextRequestID(), responseTo, wiremessage.OpMsg)
+	wm = wiremessage.AppendMsgFlags(wm, 0)
+	wm = wiremessage.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+	wm = append(wm, doc...)
+	return bsoncore.UpdateLength(wm, index, int32(len(wm[index:])))
+}
+
+func started(t *testing.T, requestID int64, commandName string, command bson.D) *event.CommandStartedEvent {
+	raw, err := bson.Marshal(command)
+	assert.NoError(t, err)
+	return &event.CommandStartedEvent{RequestID: requestID, CommandName: commandName, DatabaseName: "bank", Command: raw}
+}
+
+// record feeds the cassette the events and the wire messages of a client running a ping, a failing insert
+// and a find failing with a network error and its retry.
+func record(t *testing.T, cassette *Cassette) {
+	server, client := net.Pipe()
+	conn := &recordingConn{Conn: client, cassette: cassette}
+	var wire []byte
+
+	cassette.started(started(t, 1, "hello", bson.D{{Key: "hello", Value: 1}}))
+	cassette.started(started(t, 2, "ping", bson.D{{Key: "ping", Value: 1}}))
+	cassette.started(started(t, 3, "insert", bson.D{{Key: "insert", Value: "account"}}))
+	wire = append(wire, replyMessage(t, 1, bson.D{{Key: "ok", Value: 1}, {Key: "isWritablePrimary", Value: true}})...)
+	wire = append(wire, replyMessage(t, 2, bson.D{{Key: "ok", Value: 1}})...)
+	wire = append(wire, replyMessage(t, 3, bson.D{
+		{Key: "ok", Value: 1},
+		{Key: "n", Value: 0},
+		{Key: "writeErrors", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "code", Value: 11000}, {Key: "errmsg", Value: "E11000 duplicate key error"}}}},
+	})...)
+	wire = append(wire, replyMessage(t, 99, bson.D{{Key: "ok", Value: 1}})...)
+	go func() {
+		_, _ = server.Write(wire[:10])
+		_, _ = server.Write(wire[10:])
+		_ = server.Close()
+	}()
+
+	buf := make([]byte, 7)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			break
+		}
+	}
+	cassette.finished(1, nil, "")
+	cassette.finished(2, nil, "")
+	cassette.finished(3, nil, "")
+	cassette.started(started(t, 4, "find", bson.D{{Key: "find", Value: "account"}}))
+	cassette.finished(4, nil, "connection reset by peer")
+	cassette.started(started(t, 5, "find", bson.D{{Key: "find", Value: "account"}}))
+	cassette.finished(5, nil, "connection reset by peer")
+}
+
+func Test_CassetteRecord(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	recorder := NewCassette(filepath.Join(t.TempDir(), "account.json"))
+	record(t, recorder)
+
+	interactions := recorder.Interactions()
+	assert.Len(t, interactions, 4)
+	assert.Equal(t, "ping", interactions[0].CommandName)
+	assert.Equal(t, int32(11000), interactions[1].Reply.Lookup("writeErrors", "0", "code").Int32())
+	assert.Nil(t, interactions[2].Reply)
+	assert.Equal(t, "connection reset by peer", interactions[2].Failure)
+}
+
+var fakeHello = bson.D{
+	{Key: "ok", Value: 1},
+	{Key: "isWritablePrimary", Value: true},
+	{Key: "ismaster", Value: true},
+	{Key: "helloOk", Value: true},
+	{Key: "minWireVersion", Value: 0},
+	{Key: "maxWireVersion", Value: 13},
+	{Key: "maxBsonObjectSize", Value: 16777216},
+	{Key: "maxMessageSizeBytes", Value: 48000000},
+	{Key: "maxWriteBatchSize", Value: 100000},
+	{Key: "logicalSessionTimeoutMinutes", Value: 30},
+}
+
+// fakeServer listens on a local port like a standalone MongoDB, so that a client connects through the dialer of
+// a cassette. It fails an insert with a duplicate key error, closes the connection on a find, and answers ok otherwise.
+func fakeServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFake(t, conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveFake(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	for {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length, requestID, _, opcode, _, _ := wiremessage.ReadHeader(header)
+		wm := append(header, make([]byte, length-16)...)
+		if _, err := io.ReadFull(conn, wm[16:]); err != nil {
+			return
+		}
+		if opcode == wiremessage.OpQuery {
+			_, _ = conn.Write(queryReplyMessage(t, requestID, fakeHello))
+			continue
+		}
+
+		command, _ := msgCommand(wm[16:])
+		reply := bson.D{{Key: "ok", Value: 1}}
+		switch command.Index(0).Key() {
+		case "hello", "isMaster", "ismaster":
+			reply = fakeHello
+		case "insert":
+			reply = bson.D{
+				{Key: "ok", Value: 1},
+				{Key: "n", Value: 0},
+				{Key: "writeErrors", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "code", Value: 11000}, {Key: "errmsg", Value: "E11000 duplicate key error"}}}},
+			}
+		case "find":
+			return
+		}
+		_, _ = conn.Write(replyMessage(t, requestID, reply))
+	}
+}
+
+// queryReplyMessage is an OP_REPLY, the reply to the OP_QUERY of the handshake.
+func queryReplyMessage(t *testing.T, responseTo int32, reply bson.D) []byte {
+	doc, err := bson.Marshal(reply)
+	assert.NoError(t, err)
+	index, wm := wiremessage.AppendHeaderStart(nil, wiremessage.NextRequestID(), responseTo, wiremessage.OpReply)
+	wm = wiremessage.AppendReplyFlags(wm, 0)
+	wm = wiremessage.AppendReplyCursorID(wm, 0)
+	wm = wiremessage.AppendReplyStartingFrom(wm, 0)
+	wm = wiremessage.AppendReplyNumberReturned(wm, 1)
+	wm = append(wm, doc...)
+	return bsoncore.UpdateLength(wm, index, int32(len(wm[index:])))
+}
+
+func Test_Cassette(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	path := filepath.Join(t.TempDir(), "testdata", "account.json")
+	logger := &testLogger{}
+
+	recorder := NewCassette(path)
+	assert.Equal(t, ModeRecord, recorder.Mode())
+	var monitored int32
+	clientOpt := options.Client().ApplyURI("mongodb://" + fakeServer(t) + "/?directConnection=true").
+		SetMonitor(&event.CommandMonitor{Started: func(context.Context, *event.CommandStartedEvent) { atomic.AddInt32(&monitored, 1) }})
+	client, err := recorder.Connect(clientOpt)
+	assert.NoError(t, err)
+	defer client.Disconnect()
+	col := wrapper.NewCollection[account](client, "bank", "account")
+
+	_, err = col.InsertOne(logger, account{AccountId: 1})
+	assert.True(t, errorType.IsDuplicatedKeyErr(err))
+
+	_, err = col.FindAll(logger, bson.M{"account_id": 1})
+	assert.True(t, errorType.IsNetworkErr(err))
+	assert.NoError(t, recorder.Save())
+
+	interactions := recorder.Interactions()
+	assert.Len(t, interactions, 4)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&monitored))
+	assert.Equal(t, []string{"ping", "insert", "find", "find"}, []string{
+		interactions[0].CommandName, interactions[1].CommandName, interactions[2].CommandName, interactions[3].CommandName,
+	})
+	assert.Equal(t, int64(1), interactions[1].Command.Lookup("documents", "0", "account_id").AsInt64())
+	assert.Equal(t, int32(11000), interactions[1].Reply.Lookup("writeErrors", "0", "code").Int32())
+	assert.Nil(t, interactions[2].Reply)
+	assert.NotEmpty(t, interactions[2].Failure)
+
+	cassette := NewCassette(path)
+	assert.Equal(t, ModeReplay, cassette.Mode())
+	replaying, err := cassette.Connect(options.Client())
+	assert.NoError(t, err)
+	assert.Equal(t, interactions, cassette.Interactions())
+	col = wrapper.NewCollection[account](replaying, "bank", "account")
+
+	_, err = col.InsertOne(logger, account{AccountId: 1})
+	assert.True(t, errorType.IsDuplicatedKeyErr(err))
+
+	_, err = col.FindAll(logger, bson.M{"account_id": 2})
+	assert.True(t, errors.Is(err, ReplayMismatchErr))
+	assert.Contains(t, err.Error(), "filter.account_id is {\"$numberInt\":\"2\"}, recorded {\"$numberInt\":\"1\"}")
+
+	_, err = col.FindAll(logger, bson.M{"account_id": 1})
+	assert.True(t, errorType.IsNetworkErr(err))
+
+	_, err = col.CountDocuments(logger, bson.M{})
+	assert.True(t, errors.Is(err, ReplayMismatchErr))
+
+	t.Setenv(RecordEnv, "1")
+	assert.Equal(t, ModeRecord, NewCassette(path).Mode())
+}
+
+type testLogger struct{}
+
+func (testLogger) SlowQuery(msg string)                             {}
+func (testLogger) GetTimeoutDuration() time.Duration                { return 10 * time.Second }
+func (testLogger) GetSlowQueryDurationOfOne() time.Duration         { return time.Second }
+func (testLogger) GetSlowQueryDurationOfMany() time.Duration        { return time.Second }
+func (testLogger) GetSlowQueryDurationOfBulk() time.Duration        { return time.Second }
+func (testLogger) GetSlowQueryDurationOfAggregation() time.Duration { return time.Second }
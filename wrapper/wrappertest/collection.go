@@ -0,0 +1,866 @@
+// Package wrappertest provides an in-memory Collection with the API of wrapper.Collection, for unit tests without MongoDB.
+package wrappertest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/kjh03160/go-mongo/wrapper"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const duplicateKeyCode = 11000
+
+// Collection keeps documents in memory and evaluates queries on them like wrapper.Collection,
+// returning the same errorType errors.
+//
+// It evaluates the query operators $eq, $ne, $in, $nin, $gt, $gte, $lt, $lte, $exists, $not, $elemMatch, $and, $or and $nor,
+// the update operators $set, $setOnInsert, $unset, $inc and $push, sort, skip, limit and unique indexes.
+// Aggregate supports the $match, $sort, $skip and $limit stages.
+// Other operators, projections, collations, hints and array filters fail with UnsupportedErr as a server error.
+type Collection[T any] struct {
+	name    string
+	mu      sync.Mutex
+	docs    []bson.D
+	indexes [][]string
+}
+
+var _ wrapper.Repository[struct{}] = (*Collection[struct{}])(nil)
+
+func NewCollection[T any](name string) *Collection[T] {
+	return &Collection[T]{name: name}
+}
+
+// withOperation is errorType.WithOperation that also redacts the sensitive fields of T in the error.
+func (col *Collection[T]) withOperation(err error, operation string) error {
+	return errorType.WithOperation(errorType.WithDocumentType(err, reflect.TypeOf((*T)(nil)).Elem()), operation)
+}
+
+// withWriteOperation is errorType.WithWriteOperation that also redacts the sensitive fields of T in the error.
+func (col *Collection[T]) withWriteOperation(err error, operation string) error {
+	return errorType.WithWriteOperation(errorType.WithDocumentType(err, reflect.TypeOf((*T)(nil)).Elem()), operation)
+}
+
+func (col *Collection[T]) Name() string {
+	return col.name
+}
+
+// InSession returns the collection itself, since it has no transactions.
+func (col *Collection[T]) InSession(mongo.SessionContext) wrapper.Repository[T] {
+	return col
+}
+
+// AddUniqueIndex makes the combination of the fields unique among the documents written afterwards. _id is always unique.
+func (col *Collection[T]) AddUniqueIndex(fields ...string) {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	col.indexes = append(col.indexes, fields)
+}
+
+// Documents returns copies of the documents in the order they were inserted.
+func (col *Collection[T]) Documents() []bson.D {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	docs := make([]bson.D, len(col.docs))
+	for i, doc := range col.docs {
+		docs[i] = copyDocument(doc)
+	}
+	return docs
+}
+
+func (col *Collection[T]) FindAll(logger wrapper.Logger, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	opt := options.MergeFindOptions(opts...)
+	if err := unsupportedOptions(opt.Projection, opt.Collation, opt.Hint, nil); err != nil {
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "FindAll")
+	}
+	col.mu.Lock()
+	docs, err := col.find(filter, opt.Sort, opt.Skip, opt.Limit)
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "FindAll")
+	}
+	result := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		var data T
+		if err := decode(doc, &data); err != nil {
+			return nil, col.withOperation(errorType.DecodeError(col.name, filter, nil, nil, err), "FindAll")
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+func (col *Collection[T]) FindOne(logger wrapper.Logger, data, filter interface{}, opts ...*options.FindOneOptions) error {
+	opt := options.MergeFindOneOptions(opts...)
+	if err := unsupportedOptions(opt.Projection, opt.Collation, opt.Hint, nil); err != nil {
+		return col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "FindOne")
+	}
+	one := int64(1)
+	col.mu.Lock()
+	docs, err := col.find(filter, opt.Sort, opt.Skip, &one)
+	col.mu.Unlock()
+	if err == nil && len(docs) == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "FindOne")
+	}
+	if err := decode(docs[0], data); err != nil {
+		return col.withOperation(errorType.DecodeError(col.name, filter, nil, nil, err), "FindOne")
+	}
+	return nil
+}
+
+func (col *Collection[T]) FindOneAndModify(logger wrapper.Logger, data, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	opt := options.MergeFindOneAndUpdateOptions(opts...)
+	if err := unsupportedOptions(opt.Projection, opt.Collation, opt.Hint, opt.ArrayFilters); err != nil {
+		return col.decodeFoundOne(nil, err, data, filter, update, nil, "FindOneAndModify")
+	}
+	after := opt.ReturnDocument != nil && *opt.ReturnDocument == options.After
+	doc, err := col.findOneAndWrite(filter, update, false, opt.Sort, opt.Upsert, after)
+	if err == nil && doc == nil {
+		return col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.name, filter, nil, nil), "FindOneAndModify")
+	}
+	return col.decodeFoundOne(doc, err, data, filter, update, nil, "FindOneAndModify")
+}
+
+func (col *Collection[T]) FindOneAndReplace(logger wrapper.Logger, data, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) error {
+	opt := options.MergeFindOneAndReplaceOptions(opts...)
+	if err := unsupportedOptions(opt.Projection, opt.Collation, opt.Hint, nil); err != nil {
+		return col.decodeFoundOne(nil, err, data, filter, nil, replacement, "FindOneAndReplace")
+	}
+	after := opt.ReturnDocument != nil && *opt.ReturnDocument == options.After
+	doc, err := col.findOneAndWrite(filter, replacement, true, opt.Sort, opt.Upsert, after)
+	if err == nil && doc == nil {
+		return col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.name, filter, nil, replacement), "FindOneAndReplace")
+	}
+	return col.decodeFoundOne(doc, err, data, filter, nil, replacement, "FindOneAndReplace")
+}
+
+func (col *Collection[T]) FindOneAndDelete(logger wrapper.Logger, data, filter interface{}, opts ...*options.FindOneAndDeleteOptions) error {
+	opt := options.MergeFindOneAndDeleteOptions(opts...)
+	if err := unsupportedOptions(opt.Projection, opt.Collation, opt.Hint, nil); err != nil {
+		return col.decodeFoundOne(nil, err, data, filter, nil, nil, "FindOneAndDelete")
+	}
+	col.mu.Lock()
+	var doc bson.D
+	indexes, err := col.match(filter, opt.Sort)
+	if err == nil && len(indexes) > 0 {
+		doc = col.docs[indexes[0]]
+		col.remove(indexes[:1])
+	}
+	col.mu.Unlock()
+	return col.decodeFoundOne(doc, err, data, filter, nil, nil, "FindOneAndDelete")
+}
+
+func (col *Collection[T]) InsertOne(logger wrapper.Logger, document interface{}, opts ...*options.InsertOneOptions) (interface{}, error) {
+	col.mu.Lock()
+	id, err := col.insert(document)
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, nil, nil, document), "InsertOne")
+	}
+	return id, nil
+}
+
+func (col *Collection[T]) InsertMany(logger wrapper.Logger, documents []interface{}, opts ...*options.InsertManyOptions) (interface{}, error) {
+	opt := options.MergeInsertManyOptions(opts...)
+	ordered := opt.Ordered == nil || *opt.Ordered
+	col.mu.Lock()
+	ids := make([]interface{}, 0, len(documents))
+	var writeErrs mongo.WriteErrors
+	for i, document := range documents {
+		id, err := col.insert(document)
+		if err != nil {
+			writeErr := writeErrorOf(err)
+			writeErr.Index = i
+			writeErrs = append(writeErrs, writeErr)
+			if ordered {
+				break
+			}
+			continue
+		}
+		ids = append(ids, id)
+	}
+	col.mu.Unlock()
+	if len(writeErrs) > 0 {
+		bulkErrs := make([]mongo.BulkWriteError, len(writeErrs))
+		for i, writeErr := range writeErrs {
+			bulkErrs[i] = mongo.BulkWriteError{WriteError: writeErr}
+		}
+		return ids, col.withWriteOperation(errorType.ParseAndReturnBulkError(mongo.BulkWriteException{WriteErrors: bulkErrs}, col.name, nil, documents), "InsertMany")
+	}
+	return ids, nil
+}
+
+func (col *Collection[T]) UpdateOne(logger wrapper.Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, opt.ArrayFilters); err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, nil), "UpdateOne")
+	}
+	return col.updateResult(filter, update, nil, false, opt.Upsert, "UpdateOne")
+}
+
+func (col *Collection[T]) UpdateMany(logger wrapper.Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, opt.ArrayFilters); err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, nil), "UpdateMany")
+	}
+	return col.updateResult(filter, update, nil, true, opt.Upsert, "UpdateMany")
+}
+
+func (col *Collection[T]) ReplaceOne(logger wrapper.Logger, filter interface{}, document interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	opt := options.MergeReplaceOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, nil); err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, document), "ReplaceOne")
+	}
+	return col.updateResult(filter, nil, document, false, opt.Upsert, "ReplaceOne")
+}
+
+// UpsertOne is UpdateOne with upsert, which returns whether the document was inserted, updated or unchanged.
+func (col *Collection[T]) UpsertOne(logger wrapper.Logger, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*wrapper.UpsertResult, error) {
+	opt := options.MergeUpdateOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, opt.ArrayFilters); err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, nil), "UpsertOne")
+	}
+	col.mu.Lock()
+	result, err := col.update(filter, update, false, false, true)
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, nil), "UpsertOne")
+	}
+	return upsertResultOf(result), nil
+}
+
+// UpsertByKey writes document to the document with the same values of keyFields, or inserts it. The default key is _id.
+func (col *Collection[T]) UpsertByKey(logger wrapper.Logger, document T, keyFields ...string) (*wrapper.UpsertResult, error) {
+	filter, update, err := upsertByKeyQuery(document, keyFields...)
+	if err != nil {
+		return nil, col.withWriteOperation(err, "UpsertByKey")
+	}
+	col.mu.Lock()
+	result, err := col.update(filter, update, false, false, true)
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, nil), "UpsertByKey")
+	}
+	return upsertResultOf(result), nil
+}
+
+func (col *Collection[T]) DeleteOne(logger wrapper.Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	opt := options.MergeDeleteOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, nil); err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "DeleteOne")
+	}
+	return col.deleteResult(filter, false, "DeleteOne")
+}
+
+func (col *Collection[T]) DeleteMany(logger wrapper.Logger, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	opt := options.MergeDeleteOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, nil); err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "DeleteMany")
+	}
+	return col.deleteResult(filter, true, "DeleteMany")
+}
+
+func (col *Collection[T]) CountDocuments(logger wrapper.Logger, filter interface{}, opts ...*options.CountOptions) (int, error) {
+	opt := options.MergeCountOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, nil); err != nil {
+		return 0, col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "CountDocuments")
+	}
+	col.mu.Lock()
+	docs, err := col.find(filter, nil, opt.Skip, opt.Limit)
+	col.mu.Unlock()
+	if err != nil {
+		return 0, col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), "CountDocuments")
+	}
+	return len(docs), nil
+}
+
+func (col *Collection[T]) EstimatedDocumentCount(logger wrapper.Logger, opts ...*options.EstimatedDocumentCountOptions) (int, error) {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	return len(col.docs), nil
+}
+
+func (col *Collection[T]) BulkWrite(logger wrapper.Logger, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	opt := options.MergeBulkWriteOptions(opts...)
+	ordered := opt.Ordered == nil || *opt.Ordered
+	result := &mongo.BulkWriteResult{UpsertedIDs: map[int64]interface{}{}}
+	var bulkErrs []mongo.BulkWriteError
+	col.mu.Lock()
+	for i, model := range models {
+		if err := col.writeModel(model, i, result); err != nil {
+			writeErr := writeErrorOf(err)
+			writeErr.Index = i
+			bulkErrs = append(bulkErrs, mongo.BulkWriteError{WriteError: writeErr, Request: model})
+			if ordered {
+				break
+			}
+		}
+	}
+	col.mu.Unlock()
+	if len(bulkErrs) > 0 {
+		return result, col.withWriteOperation(errorType.ParseAndReturnBulkError(mongo.BulkWriteException{WriteErrors: bulkErrs}, col.name, models, nil), "BulkWrite")
+	}
+	return result, nil
+}
+
+// Aggregate runs a pipeline of the $match, $sort, $skip and $limit stages.
+func (col *Collection[T]) Aggregate(logger wrapper.Logger, pipeline interface{}, opts ...*options.AggregateOptions) ([]T, error) {
+	opt := options.MergeAggregateOptions(opts...)
+	if err := unsupportedOptions(nil, opt.Collation, opt.Hint, nil); err != nil {
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, pipeline, nil, nil), "Aggregate")
+	}
+	col.mu.Lock()
+	docs, err := col.aggregate(pipeline)
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, pipeline, nil, nil), "Aggregate")
+	}
+	result := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		var data T
+		if err := decode(doc, &data); err != nil {
+			return nil, col.withOperation(errorType.DecodeError(col.name, pipeline, nil, nil, err), "Aggregate")
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+func (col *Collection[T]) decodeFoundOne(doc bson.D, err error, data, filter, update, replacement interface{}, operation string) error {
+	if err == nil && doc == nil {
+		err = mongo.ErrNoDocuments
+	}
+	if err != nil {
+		return col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, replacement), operation)
+	}
+	if err := decode(doc, data); err != nil {
+		return col.withWriteOperation(errorType.DecodeError(col.name, filter, update, replacement, err), operation)
+	}
+	return nil
+}
+
+func (col *Collection[T]) updateResult(filter, update, replacement interface{}, many bool, upsert *bool, operation string) (*mongo.UpdateResult, error) {
+	col.mu.Lock()
+	var result *mongo.UpdateResult
+	var err error
+	if replacement != nil {
+		result, err = col.update(filter, replacement, true, false, upsert != nil && *upsert)
+	} else {
+		result, err = col.update(filter, update, false, many, upsert != nil && *upsert)
+	}
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, update, replacement), operation)
+	}
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return result, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.name, filter, update, replacement), operation)
+	}
+	return result, nil
+}
+
+func (col *Collection[T]) deleteResult(filter interface{}, many bool, operation string) (*mongo.DeleteResult, error) {
+	col.mu.Lock()
+	indexes, err := col.match(filter, nil)
+	if err == nil {
+		if !many && len(indexes) > 1 {
+			indexes = indexes[:1]
+		}
+		col.remove(indexes)
+	}
+	col.mu.Unlock()
+	if err != nil {
+		return nil, col.withWriteOperation(errorType.ParseAndReturnDBError(serverErrorOf(err), col.name, filter, nil, nil), operation)
+	}
+	result := &mongo.DeleteResult{DeletedCount: int64(len(indexes))}
+	if result.DeletedCount == 0 {
+		return result, col.withWriteOperation(errorType.ParseAndReturnDBError(errorType.NotMatchedAnyErr, col.name, filter, nil, nil), operation)
+	}
+	return result, nil
+}
+
+// match returns the indexes of the documents that match filter, in the order of sortDoc.
+func (col *Collection[T]) match(filter interface{}, sortDoc interface{}) ([]int, error) {
+	filterDoc, err := toDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []int
+	for i, doc := range col.docs {
+		matched, err := match(doc, filterDoc)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			indexes = append(indexes, i)
+		}
+	}
+	if sortDoc == nil {
+		return indexes, nil
+	}
+	sortD, err := toDocument(sortDoc)
+	if err != nil {
+		return nil, err
+	}
+	less, err := lessBy(sortD)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return less(col.docs[indexes[i]], col.docs[indexes[j]])
+	})
+	return indexes, nil
+}
+
+// find returns copies of the documents that match filter, sorted, skipped and limited.
+func (col *Collection[T]) find(filter interface{}, sortDoc interface{}, skip, limit *int64) ([]bson.D, error) {
+	indexes, err := col.match(filter, sortDoc)
+	if err != nil {
+		return nil, err
+	}
+	indexes = skipAndLimit(indexes, skip, limit)
+	docs := make([]bson.D, len(indexes))
+	for i, index := range indexes {
+		docs[i] = copyDocument(col.docs[index])
+	}
+	return docs, nil
+}
+
+func skipAndLimit(indexes []int, skip, limit *int64) []int {
+	if skip != nil && *skip > 0 {
+		if int(*skip) >= len(indexes) {
+			return nil
+		}
+		indexes = indexes[*skip:]
+	}
+	if limit != nil && *limit != 0 {
+		n := int(*limit)
+		if n < 0 {
+			n = -n
+		}
+		if n < len(indexes) {
+			indexes = indexes[:n]
+		}
+	}
+	return indexes
+}
+
+func (col *Collection[T]) remove(indexes []int) {
+	removed := make(map[int]bool, len(indexes))
+	for _, index := range indexes {
+		removed[index] = true
+	}
+	docs := col.docs[:0]
+	for i, doc := range col.docs {
+		if !removed[i] {
+			docs = append(docs, doc)
+		}
+	}
+	col.docs = docs
+}
+
+// insert stores document with an ObjectID as its _id if it has none, and returns the _id.
+func (col *Collection[T]) insert(document interface{}) (interface{}, error) {
+	doc, err := toDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	return col.insertDocument(doc)
+}
+
+func (col *Collection[T]) insertDocument(doc bson.D) (interface{}, error) {
+	id, ok := get(doc, "_id")
+	if !ok {
+		id = primitive.NewObjectID()
+	}
+	doc = withId(doc, id)
+	if err := col.checkUnique(doc, -1); err != nil {
+		return nil, err
+	}
+	col.docs = append(col.docs, doc)
+	return id, nil
+}
+
+// update applies update, or the replacement if replace, to the documents that match filter.
+// With upsert, it inserts the equality fields of filter with the update applied if none matches.
+func (col *Collection[T]) update(filter, update interface{}, replace, many, upsert bool) (*mongo.UpdateResult, error) {
+	updateDoc, err := toDocument(update)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := col.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !many && len(indexes) > 1 {
+		indexes = indexes[:1]
+	}
+
+	result := &mongo.UpdateResult{MatchedCount: int64(len(indexes))}
+	if len(indexes) == 0 {
+		if !upsert {
+			return result, nil
+		}
+		filterDoc, err := toDocument(filter)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := upsertDocument(filterDoc, updateDoc, replace)
+		if err != nil {
+			return nil, err
+		}
+		id, err := col.insertDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		result.UpsertedCount, result.UpsertedID = 1, id
+		return result, nil
+	}
+
+	for _, index := range indexes {
+		modified, err := col.modify(index, updateDoc, replace)
+		if err != nil {
+			return nil, err
+		}
+		if modified {
+			result.ModifiedCount++
+		}
+	}
+	return result, nil
+}
+
+// modify writes the update to the document at the index, and reports whether the document changed.
+func (col *Collection[T]) modify(index int, updateDoc bson.D, replace bool) (bool, error) {
+	var doc bson.D
+	var err error
+	if replace {
+		doc, err = replaceDocument(col.docs[index], updateDoc)
+	} else {
+		doc, err = applyUpdate(col.docs[index], updateDoc, false)
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := col.checkUnique(doc, index); err != nil {
+		return false, err
+	}
+	modified := !equal(doc, col.docs[index])
+	col.docs[index] = doc
+	return modified, nil
+}
+
+func upsertDocument(filter, updateDoc bson.D, replace bool) (bson.D, error) {
+	doc := bson.D{}
+	if !replace {
+		for _, field := range equalityFields(filter) {
+			var err error
+			if doc, err = setPath(doc, strings.Split(field.Key, "."), field.Value); err != nil {
+				return nil, err
+			}
+		}
+		return applyUpdate(doc, updateDoc, true)
+	}
+	if id, ok := get(equalityFields(filter), "_id"); ok {
+		doc = append(doc, bson.E{Key: "_id", Value: id})
+	}
+	if id, ok := get(updateDoc, "_id"); ok {
+		doc = bson.D{{Key: "_id", Value: id}}
+	}
+	if len(doc) == 0 {
+		return copyDocument(updateDoc), nil
+	}
+	return replaceDocument(doc, updateDoc)
+}
+
+// findOneAndWrite updates or replaces the first document that matches filter, and returns it before or after the write.
+func (col *Collection[T]) findOneAndWrite(filter, update interface{}, replace bool, sortDoc interface{}, upsert *bool, after bool) (bson.D, error) {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	updateDoc, err := toDocument(update)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := col.match(filter, sortDoc)
+	if err != nil {
+		return nil, err
+	}
+	if len(indexes) == 0 {
+		if upsert == nil || !*upsert {
+			return nil, nil
+		}
+		result, err := col.update(filter, update, replace, false, true)
+		if err != nil || !after {
+			return nil, err
+		}
+		return copyDocument(col.docs[col.indexOf(result.UpsertedID)]), nil
+	}
+	before := col.docs[indexes[0]]
+	if _, err := col.modify(indexes[0], updateDoc, replace); err != nil {
+		return nil, err
+	}
+	if after {
+		return copyDocument(col.docs[indexes[0]]), nil
+	}
+	return before, nil
+}
+
+func (col *Collection[T]) indexOf(id interface{}) int {
+	for i, doc := range col.docs {
+		if docId, _ := get(doc, "_id"); equal(docId, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (col *Collection[T]) writeModel(model mongo.WriteModel, index int, result *mongo.BulkWriteResult) error {
+	var updateResult *mongo.UpdateResult
+	var err error
+	switch m := model.(type) {
+	case *mongo.InsertOneModel:
+		if _, err := col.insert(m.Document); err != nil {
+			return err
+		}
+		result.InsertedCount++
+		return nil
+	case *mongo.UpdateOneModel:
+		if err = unsupportedOptions(nil, m.Collation, m.Hint, m.ArrayFilters); err == nil {
+			updateResult, err = col.update(m.Filter, m.Update, false, false, m.Upsert != nil && *m.Upsert)
+		}
+	case *mongo.UpdateManyModel:
+		if err = unsupportedOptions(nil, m.Collation, m.Hint, m.ArrayFilters); err == nil {
+			updateResult, err = col.update(m.Filter, m.Update, false, true, m.Upsert != nil && *m.Upsert)
+		}
+	case *mongo.ReplaceOneModel:
+		if err = unsupportedOptions(nil, m.Collation, m.Hint, nil); err == nil {
+			updateResult, err = col.update(m.Filter, m.Replacement, true, false, m.Upsert != nil && *m.Upsert)
+		}
+	case *mongo.DeleteOneModel, *mongo.DeleteManyModel:
+		filter, many, collation, hint := deleteModelOf(m)
+		if err := unsupportedOptions(nil, collation, hint, nil); err != nil {
+			return err
+		}
+		indexes, err := col.match(filter, nil)
+		if err != nil {
+			return err
+		}
+		if !many && len(indexes) > 1 {
+			indexes = indexes[:1]
+		}
+		col.remove(indexes)
+		result.DeletedCount += int64(len(indexes))
+		return nil
+	default:
+		return errors.Wrap(UnsupportedErr, fmt.Sprintf("%T", model))
+	}
+	if err != nil {
+		return err
+	}
+	result.MatchedCount += updateResult.MatchedCount
+	result.ModifiedCount += updateResult.ModifiedCount
+	if updateResult.UpsertedCount > 0 {
+		result.UpsertedCount++
+		result.UpsertedIDs[int64(index)] = updateResult.UpsertedID
+	}
+	return nil
+}
+
+func deleteModelOf(model mongo.WriteModel) (filter interface{}, many bool, collation *options.Collation, hint interface{}) {
+	if m, ok := model.(*mongo.DeleteManyModel); ok {
+		return m.Filter, true, m.Collation, m.Hint
+	}
+	m := model.(*mongo.DeleteOneModel)
+	return m.Filter, false, m.Collation, m.Hint
+}
+
+// unsupportedOptions returns UnsupportedErr for a projection, collation, hint or array filters,
+// which the fake collection does not apply.
+func unsupportedOptions(projection interface{}, collation *options.Collation, hint interface{}, arrayFilters *options.ArrayFilters) error {
+	switch {
+	case projection != nil:
+		return errors.Wrap(UnsupportedErr, "projection")
+	case collation != nil:
+		return errors.Wrap(UnsupportedErr, "collation")
+	case hint != nil:
+		return errors.Wrap(UnsupportedErr, "hint")
+	case arrayFilters != nil:
+		return errors.Wrap(UnsupportedErr, "arrayFilters")
+	}
+	return nil
+}
+
+func (col *Collection[T]) aggregate(pipeline interface{}) ([]bson.D, error) {
+	stages, err := toArray(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]bson.D, len(col.docs))
+	for i, doc := range col.docs {
+		docs[i] = copyDocument(doc)
+	}
+	for _, s := range stages {
+		stage, ok := s.(bson.D)
+		if !ok || len(stage) != 1 {
+			return nil, errors.New("a pipeline stage must be a document with a single field")
+		}
+		switch stage[0].Key {
+		case "$match":
+			filter, ok := stage[0].Value.(bson.D)
+			if !ok {
+				return nil, errors.New("$match needs a document")
+			}
+			matched := docs[:0]
+			for _, doc := range docs {
+				ok, err := match(doc, filter)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					matched = append(matched, doc)
+				}
+			}
+			docs = matched
+		case "$sort":
+			sortDoc, ok := stage[0].Value.(bson.D)
+			if !ok {
+				return nil, errors.New("$sort needs a document")
+			}
+			if err := sortDocuments(docs, sortDoc); err != nil {
+				return nil, err
+			}
+		case "$skip", "$limit":
+			n := int(toFloat(stage[0].Value))
+			if n < 0 {
+				return nil, errors.Errorf("%s needs a non-negative number", stage[0].Key)
+			}
+			switch {
+			case stage[0].Key == "$skip" && n < len(docs):
+				docs = docs[n:]
+			case stage[0].Key == "$skip":
+				docs = nil
+			case n < len(docs):
+				docs = docs[:n]
+			}
+		default:
+			return nil, errors.Wrap(UnsupportedErr, stage[0].Key)
+		}
+	}
+	return docs, nil
+}
+
+// checkUnique returns a duplicate key error if doc has the same _id or keys of a unique index as another document.
+// The document at skip is the one being replaced by doc.
+func (col *Collection[T]) checkUnique(doc bson.D, skip int) error {
+	indexes := append([][]string{{"_id"}}, col.indexes...)
+	for _, fields := range indexes {
+		key := indexKey(doc, fields)
+		for i, other := range col.docs {
+			if i != skip && equal(key, indexKey(other, fields)) {
+				return mongo.WriteError{
+					Code: duplicateKeyCode,
+					Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s dup key: %s",
+						col.name, strings.Join(fields, "_1_")+"_1", errorType.RedactJSON(key)),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func indexKey(doc bson.D, fields []string) bson.A {
+	key := make(bson.A, len(fields))
+	for i, field := range fields {
+		if values := lookup(doc, strings.Split(field, ".")); len(values) > 0 {
+			key[i] = values[0]
+		}
+	}
+	return key
+}
+
+// serverErrorOf returns err as the server would, a write exception for a duplicate key and a BadValue error otherwise.
+func serverErrorOf(err error) error {
+	var writeErr mongo.WriteError
+	if errors.As(err, &writeErr) {
+		return mongo.WriteException{WriteErrors: mongo.WriteErrors{writeErr}}
+	}
+	if err == mongo.ErrNoDocuments {
+		return err
+	}
+	return mongo.CommandError{Code: 2, Name: "BadValue", Message: err.Error(), Wrapped: err}
+}
+
+func writeErrorOf(err error) mongo.WriteError {
+	var writeErr mongo.WriteError
+	if errors.As(err, &writeErr) {
+		return writeErr
+	}
+	return mongo.WriteError{Code: 2, Message: err.Error()}
+}
+
+func decode(doc bson.D, data interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, data)
+}
+
+func upsertResultOf(result *mongo.UpdateResult) *wrapper.UpsertResult {
+	switch {
+	case result.UpsertedCount > 0:
+		return &wrapper.UpsertResult{Outcome: wrapper.UpsertInserted, UpsertedID: result.UpsertedID}
+	case result.ModifiedCount > 0:
+		return &wrapper.UpsertResult{Outcome: wrapper.UpsertUpdated}
+	}
+	return &wrapper.UpsertResult{Outcome: wrapper.UpsertUnchanged}
+}
+
+func upsertByKeyQuery(document interface{}, keyFields ...string) (bson.D, bson.D, error) {
+	if len(keyFields) == 0 {
+		keyFields = []string{"_id"}
+	}
+	doc, err := toDocument(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter := bson.D{}
+	for _, field := range keyFields {
+		values := lookup(doc, strings.Split(field, "."))
+		if len(values) == 0 {
+			return nil, nil, errors.Wrap(errorType.UpsertKeyMissingErr, field)
+		}
+		filter = append(filter, bson.E{Key: field, Value: values[0]})
+	}
+	set, setOnInsert := bson.D{}, bson.D{}
+	for _, element := range doc {
+		if _, isKey := get(filter, element.Key); isKey {
+			continue
+		}
+		if element.Key == "_id" {
+			setOnInsert = append(setOnInsert, element)
+		} else {
+			set = append(set, element)
+		}
+	}
+	update := bson.D{}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(setOnInsert) > 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: setOnInsert})
+	}
+	if len(update) == 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: filter})
+	}
+	return filter, update, nil
+}
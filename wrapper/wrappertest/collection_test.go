@@ -0,0 +1,273 @@
+package wrappertest
+
+import (
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/kjh03160/go-mongo/wrapper"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type product struct {
+	Name  string `bson:"name"`
+	Price int    `bson:"price"`
+}
+
+type account struct {
+	AccountId int       `bson:"account_id"`
+	Limit     int       `bson:"limit"`
+	Tags      []string  `bson:"tags,omitempty"`
+	Products  []product `bson:"products,omitempty"`
+}
+
+func newAccounts(t *testing.T) *Collection[account] {
+	col := NewCollection[account]("account")
+	_, err := col.InsertMany(nil, []interface{}{
+		account{AccountId: 1, Limit: 10, Tags: []string{"gold"}, Products: []product{{Name: "card", Price: 5}}},
+		account{AccountId: 2, Limit: 30, Products: []product{{Name: "card", Price: 20}, {Name: "loan", Price: 50}}},
+		account{AccountId: 3, Limit: 20, Tags: []string{"silver", "gold"}},
+	})
+	assert.NoError(t, err)
+	return col
+}
+
+func accountIds(accounts []account) []int {
+	ids := make([]int, len(accounts))
+	for i, a := range accounts {
+		ids[i] = a.AccountId
+	}
+	return ids
+}
+
+func Test_FindAll(t *testing.T) {
+	col := newAccounts(t)
+
+	tests := []struct {
+		name   string
+		filter interface{}
+		opts   *options.FindOptions
+		want   []int
+	}{
+		{"all", bson.M{}, nil, []int{1, 2, 3}},
+		{"equal", bson.M{"account_id": 2}, nil, []int{2}},
+		{"array contains", bson.M{"tags": "gold"}, nil, []int{1, 3}},
+		{"dotted path in array", bson.M{"products.name": "loan"}, nil, []int{2}},
+		{"$in", bson.M{"account_id": bson.M{"$in": bson.A{1, 3}}}, nil, []int{1, 3}},
+		{"$gt and $lte", bson.M{"limit": bson.M{"$gt": 10, "$lte": 30}}, nil, []int{2, 3}},
+		{"$exists", bson.M{"tags": bson.M{"$exists": false}}, nil, []int{2}},
+		{"$or", bson.M{"$or": bson.A{bson.M{"account_id": 1}, bson.M{"limit": 20}}}, nil, []int{1, 3}},
+		{"$and", bson.D{{Key: "$and", Value: bson.A{bson.M{"tags": "gold"}, bson.M{"limit": bson.M{"$gt": 10}}}}}, nil, []int{3}},
+		{"$elemMatch", bson.M{"products": bson.M{"$elemMatch": bson.M{"name": "card", "price": bson.M{"$gt": 10}}}}, nil, []int{2}},
+		{"sort", bson.M{}, options.Find().SetSort(bson.D{{Key: "limit", Value: -1}}), []int{2, 3, 1}},
+		{"skip and limit", bson.M{}, options.Find().SetSort(bson.M{"limit": 1}).SetSkip(1).SetLimit(1), []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []*options.FindOptions
+			if tt.opts != nil {
+				opts = append(opts, tt.opts)
+			}
+			result, err := col.FindAll(nil, tt.filter, opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, accountIds(result))
+		})
+	}
+
+	_, err := col.FindAll(nil, bson.M{"name": bson.M{"$regex": "^a"}})
+	assert.True(t, errors.Is(err, UnsupportedErr))
+	var queryErr errorType.QueryError
+	assert.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, "FindAll", queryErr.Operation())
+}
+
+func Test_FindOne(t *testing.T) {
+	col := newAccounts(t)
+
+	var result account
+	err := col.FindOne(nil, &result, bson.M{}, options.FindOne().SetSort(bson.M{"limit": -1}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.AccountId)
+
+	err = col.FindOne(nil, &result, bson.M{"account_id": 9})
+	assert.True(t, errorType.IsNotFoundErr(err))
+}
+
+func Test_Update(t *testing.T) {
+	t.Run("operators", func(t *testing.T) {
+		col := newAccounts(t)
+		result, err := col.UpdateOne(nil, bson.M{"account_id": 1}, bson.D{
+			{Key: "$set", Value: bson.M{"limit": 15}},
+			{Key: "$inc", Value: bson.M{"products.0.price": 1}},
+			{Key: "$push", Value: bson.M{"tags": bson.M{"$each": bson.A{"vip"}}}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.ModifiedCount)
+
+		var updated account
+		assert.NoError(t, col.FindOne(nil, &updated, bson.M{"account_id": 1}))
+		assert.Equal(t, account{AccountId: 1, Limit: 15, Tags: []string{"gold", "vip"}, Products: []product{{Name: "card", Price: 6}}}, updated)
+
+		_, err = col.UpdateOne(nil, bson.M{"account_id": 1}, bson.M{"$unset": bson.M{"tags": ""}})
+		assert.NoError(t, err)
+		var unset account
+		assert.NoError(t, col.FindOne(nil, &unset, bson.M{"account_id": 1}))
+		assert.Nil(t, unset.Tags)
+	})
+
+	t.Run("many", func(t *testing.T) {
+		col := newAccounts(t)
+		result, err := col.UpdateMany(nil, bson.M{"tags": "gold"}, bson.M{"$inc": bson.M{"limit": 5}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), result.MatchedCount)
+		count, _ := col.CountDocuments(nil, bson.M{"limit": bson.M{"$in": bson.A{15, 25}}})
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("not matched", func(t *testing.T) {
+		col := newAccounts(t)
+		_, err := col.UpdateOne(nil, bson.M{"account_id": 9}, bson.M{"$set": bson.M{"limit": 1}})
+		assert.True(t, errorType.IsNotFoundErr(err))
+		assert.True(t, errors.Is(err, errorType.NotMatchedAnyErr))
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		col := newAccounts(t)
+		result, err := col.UpsertOne(nil, bson.M{"account_id": 9}, bson.M{"$set": bson.M{"limit": 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, wrapper.UpsertInserted, result.Outcome)
+
+		result, err = col.UpsertByKey(nil, account{AccountId: 9, Limit: 1}, "account_id")
+		assert.NoError(t, err)
+		assert.Equal(t, wrapper.UpsertUnchanged, result.Outcome)
+
+		result, err = col.UpsertByKey(nil, account{AccountId: 9, Limit: 2}, "account_id")
+		assert.NoError(t, err)
+		assert.Equal(t, wrapper.UpsertUpdated, result.Outcome)
+		assert.Len(t, col.Documents(), 4)
+	})
+
+	t.Run("find one and modify", func(t *testing.T) {
+		col := newAccounts(t)
+		var before, after account
+		assert.NoError(t, col.FindOneAndModify(nil, &before, bson.M{"account_id": 1}, bson.M{"$inc": bson.M{"limit": 1}}))
+		assert.NoError(t, col.FindOneAndModify(nil, &after, bson.M{"account_id": 1}, bson.M{"$inc": bson.M{"limit": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)))
+		assert.Equal(t, 10, before.Limit)
+		assert.Equal(t, 12, after.Limit)
+	})
+
+	t.Run("_id is immutable", func(t *testing.T) {
+		col := newAccounts(t)
+		_, err := col.UpdateOne(nil, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"_id": 1}})
+		assert.Error(t, err)
+		assert.True(t, errorType.IsDBInternalErr(err))
+	})
+}
+
+func Test_ReplaceAndDelete(t *testing.T) {
+	col := newAccounts(t)
+
+	_, err := col.ReplaceOne(nil, bson.M{"account_id": 1}, account{AccountId: 1, Limit: 99})
+	assert.NoError(t, err)
+	var replaced account
+	assert.NoError(t, col.FindOne(nil, &replaced, bson.M{"account_id": 1}))
+	assert.Equal(t, account{AccountId: 1, Limit: 99}, replaced)
+
+	var deleted account
+	assert.NoError(t, col.FindOneAndDelete(nil, &deleted, bson.M{}, options.FindOneAndDelete().SetSort(bson.M{"limit": 1})))
+	assert.Equal(t, 3, deleted.AccountId)
+
+	result, err := col.DeleteMany(nil, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.DeletedCount)
+
+	_, err = col.DeleteOne(nil, bson.M{})
+	assert.True(t, errors.Is(err, errorType.NotMatchedAnyErr))
+	count, _ := col.EstimatedDocumentCount(nil)
+	assert.Equal(t, 0, count)
+}
+
+func Test_UniqueIndex(t *testing.T) {
+	col := newAccounts(t)
+	col.AddUniqueIndex("account_id")
+
+	_, err := col.InsertOne(nil, account{AccountId: 1})
+	assert.True(t, errorType.IsDuplicatedKeyErr(err))
+	var queryErr errorType.QueryError
+	assert.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, 11000, queryErr.Code())
+
+	_, err = col.UpdateOne(nil, bson.M{"account_id": 2}, bson.M{"$set": bson.M{"account_id": 3}})
+	assert.True(t, errorType.IsDuplicatedKeyErr(err))
+
+	ids, err := col.InsertMany(nil, []interface{}{account{AccountId: 4}, account{AccountId: 1}, account{AccountId: 5}},
+		options.InsertMany().SetOrdered(false))
+	assert.True(t, errorType.IsBulkErr(err))
+	assert.Len(t, ids, 2)
+	var bulkErr *errorType.BulkError
+	assert.True(t, errors.As(err, &bulkErr))
+	assert.Equal(t, 1, bulkErr.Errors[0].Index)
+	assert.True(t, errorType.IsDuplicatedKeyErr(bulkErr.Errors[0].Err))
+}
+
+func Test_BulkWrite(t *testing.T) {
+	col := newAccounts(t)
+	col.AddUniqueIndex("account_id")
+
+	result, err := col.BulkWrite(nil, []mongo.WriteModel{
+		mongo.NewInsertOneModel().SetDocument(account{AccountId: 4}),
+		mongo.NewUpdateOneModel().SetFilter(bson.M{"account_id": 1}).SetUpdate(bson.M{"$set": bson.M{"limit": 0}}),
+		mongo.NewUpdateOneModel().SetFilter(bson.M{"account_id": 5}).SetUpdate(bson.M{"$set": bson.M{"limit": 0}}).SetUpsert(true),
+		mongo.NewDeleteManyModel().SetFilter(bson.M{"limit": bson.M{"$gte": 20}}),
+		mongo.NewInsertOneModel().SetDocument(account{AccountId: 1}),
+		mongo.NewInsertOneModel().SetDocument(account{AccountId: 6}),
+	})
+	assert.True(t, errorType.IsBulkErr(err))
+	assert.Equal(t, int64(1), result.InsertedCount)
+	assert.Equal(t, int64(1), result.ModifiedCount)
+	assert.Equal(t, int64(1), result.UpsertedCount)
+	assert.Equal(t, int64(2), result.DeletedCount)
+	count, _ := col.EstimatedDocumentCount(nil)
+	assert.Equal(t, 3, count)
+}
+
+func Test_Aggregate(t *testing.T) {
+	col := newAccounts(t)
+
+	result, err := col.Aggregate(nil, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"limit": bson.M{"$gte": 20}}}},
+		{{Key: "$sort", Value: bson.M{"limit": 1}}},
+		{{Key: "$limit", Value: 1}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, accountIds(result))
+
+	_, err = col.Aggregate(nil, mongo.Pipeline{{{Key: "$group", Value: bson.M{"_id": nil}}}})
+	assert.True(t, errors.Is(err, UnsupportedErr))
+}
+
+func Test_UnsupportedOptions(t *testing.T) {
+	col := newAccounts(t)
+
+	_, err := col.FindAll(nil, bson.M{}, options.Find().SetProjection(bson.M{"name": 1}))
+	assert.True(t, errors.Is(err, UnsupportedErr))
+	var result account
+	err = col.FindOne(nil, &result, bson.M{"account_id": 1}, options.FindOne().SetCollation(&options.Collation{Locale: "en"}))
+	assert.True(t, errors.Is(err, UnsupportedErr))
+	err = col.FindOneAndModify(nil, &result, bson.M{"account_id": 1}, bson.M{"$set": bson.M{"name": "b"}}, options.FindOneAndUpdate().SetHint("account_id_1"))
+	assert.True(t, errors.Is(err, UnsupportedErr))
+	_, err = col.UpdateMany(nil, bson.M{}, bson.M{"$set": bson.M{"name": "b"}}, options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"x": 1}}}))
+	assert.True(t, errors.Is(err, UnsupportedErr))
+	_, err = col.DeleteOne(nil, bson.M{"account_id": 1}, options.Delete().SetHint("account_id_1"))
+	assert.True(t, errors.Is(err, UnsupportedErr))
+	_, err = col.BulkWrite(nil, []mongo.WriteModel{mongo.NewDeleteOneModel().SetFilter(bson.M{"account_id": 1}).SetHint("account_id_1")})
+	assert.ErrorContains(t, err, UnsupportedErr.Error())
+
+	count, err := col.CountDocuments(nil, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,61 @@
+package wrappertest
+
+import (
+	"testing"
+
+	"github.com/kjh03160/go-mongo/errorType"
+	"github.com/kjh03160/go-mongo/wrapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// limitOf is a function under test that depends only on the repository.
+func limitOf(repo wrapper.Repository[account], accountId int) (int, error) {
+	var result account
+	if err := repo.FindOne(nil, &result, bson.M{"account_id": accountId}); err != nil {
+		return 0, err
+	}
+	return result.Limit, nil
+}
+
+func Test_Repository(t *testing.T) {
+	t.Run("fake", func(t *testing.T) {
+		limit, err := limitOf(newAccounts(t), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 30, limit)
+	})
+
+	t.Run("mock", func(t *testing.T) {
+		repo := NewMockRepository[account](t)
+		repo.On("FindOne", nil, mock.Anything, bson.M{"account_id": 2}).Run(func(args mock.Arguments) {
+			args.Get(1).(*account).Limit = 5
+		}).Return(nil).Once()
+		repo.On("FindOne", nil, mock.Anything, bson.M{"account_id": 9}).
+			Return(errorType.ParseAndReturnDBError(mongo.ErrNoDocuments, "account", nil, nil, nil)).Once()
+
+		limit, err := limitOf(repo, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, limit)
+
+		_, err = limitOf(repo, 9)
+		assert.True(t, errorType.IsNotFoundErr(err))
+	})
+
+	t.Run("mock in session", func(t *testing.T) {
+		var sessCtx mongo.SessionContext
+		bound := NewMockRepository[account](t)
+		bound.On("FindOne", nil, mock.Anything, bson.M{"account_id": 2}).Return(nil).Once()
+		repo := NewMockSoftDeleteRepository[account](t)
+		repo.On("InSession", sessCtx).Return(bound).Once()
+		repo.On("Restore", nil, bson.M{"account_id": 2}).Return(&mongo.UpdateResult{ModifiedCount: 1}, nil).Once()
+
+		result, err := repo.Restore(nil, bson.M{"account_id": 2})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.ModifiedCount)
+
+		_, err = limitOf(repo.InSession(sessCtx), 2)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,323 @@
+package wrappertest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// UnsupportedErr is returned for the operators and stages the fake collection does not evaluate.
+var UnsupportedErr = errors.New("not supported by the fake collection")
+
+// toDocument returns v as a document of the types the driver decodes to, such as int32 and bson.A.
+func toDocument(v interface{}) (bson.D, error) {
+	if v == nil {
+		return bson.D{}, nil
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// toArray returns v, such as a mongo.Pipeline, as an array of the types the driver decodes to.
+func toArray(v interface{}) (bson.A, error) {
+	doc, err := toDocument(bson.D{{Key: "a", Value: v}})
+	if err != nil {
+		return nil, err
+	}
+	array, ok := doc[0].Value.(bson.A)
+	if !ok {
+		return nil, errors.Errorf("%T is not an array", v)
+	}
+	return array, nil
+}
+
+func get(doc bson.D, key string) (interface{}, bool) {
+	for _, element := range doc {
+		if element.Key == key {
+			return element.Value, true
+		}
+	}
+	return nil, false
+}
+
+// lookup returns the values at the dotted path. Arrays on the path are traversed element by element.
+func lookup(v interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{v}
+	}
+	switch x := v.(type) {
+	case bson.D:
+		if value, ok := get(x, path[0]); ok {
+			return lookup(value, path[1:])
+		}
+	case bson.A:
+		if index, err := strconv.Atoi(path[0]); err == nil {
+			if index < len(x) {
+				return lookup(x[index], path[1:])
+			}
+			return nil
+		}
+		var values []interface{}
+		for _, item := range x {
+			if _, ok := item.(bson.D); ok {
+				values = append(values, lookup(item, path)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// candidates returns values and the elements of the arrays among them, which a condition on a field is checked against.
+func candidates(values []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+		if array, ok := value.(bson.A); ok {
+			result = append(result, array...)
+		}
+	}
+	return result
+}
+
+// match reports whether doc matches filter.
+func match(doc bson.D, filter bson.D) (bool, error) {
+	for _, element := range filter {
+		ok, err := matchElement(doc, element)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchElement(doc bson.D, element bson.E) (bool, error) {
+	switch element.Key {
+	case "$and", "$or", "$nor":
+		filters, ok := element.Value.(bson.A)
+		if !ok {
+			return false, errors.Errorf("%s needs an array", element.Key)
+		}
+		for _, f := range filters {
+			subFilter, ok := f.(bson.D)
+			if !ok {
+				return false, errors.Errorf("%s needs an array of documents", element.Key)
+			}
+			matched, err := match(doc, subFilter)
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case element.Key == "$and" && !matched:
+				return false, nil
+			case element.Key == "$or" && matched:
+				return true, nil
+			case element.Key == "$nor" && matched:
+				return false, nil
+			}
+		}
+		return element.Key != "$or", nil
+	}
+	if strings.HasPrefix(element.Key, "$") {
+		return false, errors.Wrap(UnsupportedErr, element.Key)
+	}
+	return matchCondition(lookup(doc, strings.Split(element.Key, ".")), element.Value)
+}
+
+// matchCondition reports whether the values of a field match a condition, which is a value or a document of operators.
+func matchCondition(values []interface{}, condition interface{}) (bool, error) {
+	if operators, ok := condition.(bson.D); ok && isOperators(operators) {
+		for _, operator := range operators {
+			matched, err := matchOperator(values, operator)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return matchEqual(values, condition), nil
+}
+
+func isOperators(doc bson.D) bool {
+	return len(doc) > 0 && strings.HasPrefix(doc[0].Key, "$")
+}
+
+func matchEqual(values []interface{}, value interface{}) bool {
+	if len(values) == 0 {
+		return value == nil
+	}
+	for _, candidate := range candidates(values) {
+		if equal(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(values []interface{}, operator bson.E) (bool, error) {
+	switch operator.Key {
+	case "$eq":
+		return matchEqual(values, operator.Value), nil
+	case "$ne":
+		return !matchEqual(values, operator.Value), nil
+	case "$in", "$nin":
+		list, ok := operator.Value.(bson.A)
+		if !ok {
+			return false, errors.Errorf("%s needs an array", operator.Key)
+		}
+		in := false
+		for _, value := range list {
+			if matchEqual(values, value) {
+				in = true
+				break
+			}
+		}
+		return in == (operator.Key == "$in"), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, candidate := range candidates(values) {
+			if typeOrder(candidate) != typeOrder(operator.Value) {
+				continue
+			}
+			c := compare(candidate, operator.Value)
+			if (operator.Key == "$gt" && c > 0) || (operator.Key == "$gte" && c >= 0) ||
+				(operator.Key == "$lt" && c < 0) || (operator.Key == "$lte" && c <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$exists":
+		exists, ok := operator.Value.(bool)
+		if !ok {
+			return false, errors.New("$exists needs a bool")
+		}
+		return (len(values) > 0) == exists, nil
+	case "$not":
+		matched, err := matchCondition(values, operator.Value)
+		return !matched, err
+	case "$elemMatch":
+		condition, ok := operator.Value.(bson.D)
+		if !ok {
+			return false, errors.New("$elemMatch needs a document")
+		}
+		for _, value := range values {
+			array, ok := value.(bson.A)
+			if !ok {
+				continue
+			}
+			for _, item := range array {
+				matched, err := matchElemMatch(item, condition)
+				if err != nil {
+					return false, err
+				}
+				if matched {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	return false, errors.Wrap(UnsupportedErr, operator.Key)
+}
+
+// matchElemMatch reports whether an element of an array matches the condition of $elemMatch,
+// which is a filter for documents or operators for values.
+func matchElemMatch(item interface{}, condition bson.D) (bool, error) {
+	if isOperators(condition) && condition[0].Key != "$and" && condition[0].Key != "$or" && condition[0].Key != "$nor" {
+		return matchCondition([]interface{}{item}, condition)
+	}
+	doc, ok := item.(bson.D)
+	if !ok {
+		return false, nil
+	}
+	return match(doc, condition)
+}
+
+// equalityFields returns the fields of filter that an upsert inserts, the ones compared by equality or $eq.
+func equalityFields(filter bson.D) bson.D {
+	fields := bson.D{}
+	for _, element := range filter {
+		switch {
+		case element.Key == "$and":
+			if filters, ok := element.Value.(bson.A); ok {
+				for _, f := range filters {
+					if subFilter, ok := f.(bson.D); ok {
+						fields = append(fields, equalityFields(subFilter)...)
+					}
+				}
+			}
+		case strings.HasPrefix(element.Key, "$"):
+		default:
+			operators, ok := element.Value.(bson.D)
+			if !ok || !isOperators(operators) {
+				fields = append(fields, element)
+				continue
+			}
+			if value, ok := get(operators, "$eq"); ok {
+				fields = append(fields, bson.E{Key: element.Key, Value: value})
+			}
+		}
+	}
+	return fields
+}
+
+// sortDocuments sorts docs stably by the sort document, such as {"age": -1, "name": 1}.
+func sortDocuments(docs []bson.D, sortDoc bson.D) error {
+	less, err := lessBy(sortDoc)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return less(docs[i], docs[j])
+	})
+	return nil
+}
+
+// lessBy returns the order of documents by the sort document.
+func lessBy(sortDoc bson.D) (func(a, b bson.D) bool, error) {
+	directions := make([]int, len(sortDoc))
+	for i, element := range sortDoc {
+		switch direction := toFloat(element.Value); direction {
+		case 1, -1:
+			directions[i] = int(direction)
+		default:
+			return nil, errors.Wrap(UnsupportedErr, fmt.Sprintf("sort %v", element.Value))
+		}
+	}
+	return func(a, b bson.D) bool {
+		for k, element := range sortDoc {
+			path := strings.Split(element.Key, ".")
+			if c := compare(sortKey(a, path, directions[k]), sortKey(b, path, directions[k])); c != 0 {
+				return c*directions[k] < 0
+			}
+		}
+		return false
+	}, nil
+}
+
+// sortKey returns the value of a document to sort by. For arrays, it is the smallest element in ascending order
+// and the largest in descending order.
+func sortKey(doc bson.D, path []string, direction int) interface{} {
+	values := candidates(lookup(doc, path))
+	var key interface{}
+	found := false
+	for _, value := range values {
+		if _, isArray := value.(bson.A); isArray {
+			continue
+		}
+		if !found || compare(value, key)*direction < 0 {
+			key, found = value, true
+		}
+	}
+	return key
+}
@@ -0,0 +1,306 @@
+package wrappertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo/address"
+	"go.mongodb.org/mongo-driver/mongo/description"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// ReplayMismatchErr is returned when a replaying client sends a command other than the next recorded one,
+// the next one with other fields, or more commands than recorded.
+var ReplayMismatchErr = errors.New("command does not match the recorded interaction")
+
+const replayAddress = address.Address("replay:27017")
+
+// replayDescription describes a replica set primary, so that sessions, transactions and retryable writes
+// work as they did while recording.
+var replayDescription = description.Server{
+	Addr:                  replayAddress,
+	CanonicalAddr:         replayAddress,
+	Kind:                  description.RSPrimary,
+	MaxDocumentSize:       16777216,
+	MaxMessageSize:        48000000,
+	MaxBatchCount:         100000,
+	SessionTimeoutMinutes: 30,
+	WireVersion:           &description.VersionRange{Max: topology.SupportedWireVersions.Max},
+}
+
+// replay loads the golden file and makes opt use a deployment that serves its replies.
+func (c *Cassette) replay(opt *options.ClientOptions) error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	var golden []goldenInteraction
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return errors.Wrap(err, c.path)
+	}
+	c.interactions = make([]Interaction, len(golden))
+	for i, g := range golden {
+		if c.interactions[i], err = g.interaction(); err != nil {
+			return errors.Wrap(err, c.path)
+		}
+	}
+	opt.Deployment = &replayDeployment{conn: &replayConn{cassette: c}}
+	return nil
+}
+
+// nextReply checks that the command is the next recorded one, and returns its reply.
+// The commands are compared without their volatileFields, and the error tells the fields that differ.
+func (c *Cassette) nextReply(command bson.Raw) (*Interaction, error) {
+	commandName := command.Index(0).Key()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.next >= len(c.interactions) {
+		return nil, errors.Wrapf(ReplayMismatchErr, "%s after the %d recorded commands", commandName, len(c.interactions))
+	}
+	interaction := &c.interactions[c.next]
+	if interaction.CommandName != commandName {
+		return nil, errors.Wrapf(ReplayMismatchErr, "%s instead of %s at %d", commandName, interaction.CommandName, c.next)
+	}
+	if diff := commandDiff(interaction.Command, command); diff != "" {
+		return nil, errors.Wrapf(ReplayMismatchErr, "%s at %d differs from the recorded one: %s", commandName, c.next, diff)
+	}
+	c.next++
+	return interaction, nil
+}
+
+// volatileFields of a command differ between runs of the same test: the session, the cluster time and the transaction
+// number, the database, which tests often name at random, the read preference, which depends on the topology that
+// a replaying client does not have, and maxTimeMS, which is the time left of a client timeout.
+var volatileFields = map[string]bool{
+	"lsid": true, "$clusterTime": true, "txnNumber": true, "$db": true, "$readPreference": true, "maxTimeMS": true,
+}
+
+// commandDiff returns the fields of a command that differ from the recorded one, or an empty string if none does.
+// ObjectIds and dates are compared by their types only, since the driver and the timestamps generate them while running.
+func commandDiff(recorded, sent bson.Raw) string {
+	var diffs []string
+	diffDocument(&diffs, "", recorded, sent)
+	return strings.Join(diffs, ", ")
+}
+
+func diffDocument(diffs *[]string, path string, recorded, sent bson.Raw) {
+	recordedElements, _ := recorded.Elements()
+	for _, element := range recordedElements {
+		key := element.Key()
+		if path == "" && volatileFields[key] {
+			continue
+		}
+		value, err := sent.LookupErr(key)
+		if err != nil {
+			*diffs = append(*diffs, fmt.Sprintf("%s is missing, recorded %s", path+key, element.Value()))
+			continue
+		}
+		diffValue(diffs, path+key, element.Value(), value)
+	}
+	sentElements, _ := sent.Elements()
+	for _, element := range sentElements {
+		key := element.Key()
+		if path == "" && volatileFields[key] {
+			continue
+		}
+		if _, err := recorded.LookupErr(key); err != nil {
+			*diffs = append(*diffs, fmt.Sprintf("%s is %s, not recorded", path+key, element.Value()))
+		}
+	}
+}
+
+func diffValue(diffs *[]string, path string, recorded, sent bson.RawValue) {
+	switch {
+	case recorded.Type != sent.Type:
+		*diffs = append(*diffs, fmt.Sprintf("%s is %s, recorded %s", path, sent, recorded))
+	case recorded.Type == bsontype.EmbeddedDocument:
+		diffDocument(diffs, path+".", recorded.Document(), sent.Document())
+	case recorded.Type == bsontype.Array:
+		recordedValues, _ := recorded.Array().Values()
+		sentValues, _ := sent.Array().Values()
+		if len(recordedValues) != len(sentValues) {
+			*diffs = append(*diffs, fmt.Sprintf("%s has %d values, recorded %d", path, len(sentValues), len(recordedValues)))
+			return
+		}
+		for i := range recordedValues {
+			diffValue(diffs, path+"."+strconv.Itoa(i), recordedValues[i], sentValues[i])
+		}
+	case recorded.Type == bsontype.ObjectID || recorded.Type == bsontype.DateTime:
+	case !recorded.Equal(sent):
+		*diffs = append(*diffs, fmt.Sprintf("%s is %s, recorded %s", path, sent, recorded))
+	}
+}
+
+// replayConn implements driver.Connection with the replies of a cassette.
+type replayConn struct {
+	cassette *Cassette
+	reply    *Interaction
+	err      error
+}
+
+func (conn *replayConn) WriteWireMessage(_ context.Context, wm []byte) error {
+	_, _, _, _, rem, ok := wiremessage.ReadHeader(wm)
+	if !ok {
+		return errors.New("invalid wire message")
+	}
+	command, ok := msgCommand(rem)
+	if !ok {
+		return errors.New("only OP_MSG is replayed")
+	}
+	reply, err := conn.cassette.nextReply(command)
+	if wiremessage.IsMsgMoreToCome(wm) {
+		return err
+	}
+	conn.reply, conn.err = reply, err
+	return nil
+}
+
+// msgCommand returns the command of an OP_MSG after its header, with its document sequences as arrays
+// like the command of the command monitor, which is the one recorded.
+func msgCommand(src []byte) (bson.Raw, bool) {
+	_, rem, ok := wiremessage.ReadMsgFlags(src)
+	if !ok {
+		return nil, false
+	}
+	var command bsoncore.Document
+	var identifiers []string
+	var sequences [][]bsoncore.Document
+	for len(rem) > 0 {
+		var sectionType wiremessage.SectionType
+		if sectionType, rem, ok = wiremessage.ReadMsgSectionType(rem); !ok {
+			return nil, false
+		}
+		if sectionType == wiremessage.SingleDocument {
+			command, rem, ok = wiremessage.ReadMsgSectionSingleDocument(rem)
+		} else {
+			var identifier string
+			var docs []bsoncore.Document
+			identifier, docs, rem, ok = wiremessage.ReadMsgSectionDocumentSequence(rem)
+			identifiers, sequences = append(identifiers, identifier), append(sequences, docs)
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	if command == nil {
+		return nil, false
+	}
+	if len(sequences) == 0 {
+		return bson.Raw(command), true
+	}
+
+	index, dst := bsoncore.AppendDocumentStart(nil)
+	dst = append(dst, command[4:len(command)-1]...)
+	for i, docs := range sequences {
+		var arrayIndex int32
+		arrayIndex, dst = bsoncore.AppendArrayElementStart(dst, identifiers[i])
+		for j, doc := range docs {
+			dst = bsoncore.AppendDocumentElement(dst, strconv.Itoa(j), doc)
+		}
+		dst, _ = bsoncore.AppendArrayEnd(dst, arrayIndex)
+	}
+	dst, _ = bsoncore.AppendDocumentEnd(dst, index)
+	return bson.Raw(dst), true
+}
+
+func (conn *replayConn) ReadWireMessage(ctx context.Context, dst []byte) ([]byte, error) {
+	reply, err := conn.reply, conn.err
+	conn.reply, conn.err = nil, nil
+	if err != nil {
+		return dst, err
+	}
+	if reply == nil {
+		return dst, errors.New("no command to reply to")
+	}
+	if reply.Reply == nil {
+		return dst, failureOf(reply.Failure)
+	}
+
+	var index int32
+	index, dst = wiremessage.AppendHeaderStart(dst, wiremessage.NextRequestID(), 0, wiremessage.OpMsg)
+	dst = wiremessage.AppendMsgFlags(dst, 0)
+	dst = wiremessage.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, reply.Reply...)
+	return bsoncore.UpdateLength(dst, index, int32(len(dst[index:]))), nil
+}
+
+// failureOf returns the recorded failure of a command without a reply as the error of reading the reply.
+// A timeout wraps context.DeadlineExceeded, so that it is still a timeout.
+func failureOf(failure string) error {
+	if strings.Contains(failure, context.DeadlineExceeded.Error()) {
+		return errors.Wrap(context.DeadlineExceeded, failure)
+	}
+	return errors.New(failure)
+}
+
+func (conn *replayConn) Description() description.Server { return replayDescription }
+func (conn *replayConn) Close() error                    { return nil }
+func (conn *replayConn) ID() string                      { return "replay" }
+func (conn *replayConn) ServerConnectionID() *int32      { return nil }
+func (conn *replayConn) Address() address.Address        { return replayAddress }
+func (conn *replayConn) Stale() bool                     { return false }
+
+// replayDeployment is a single server deployment whose connection is a replayConn.
+type replayDeployment struct {
+	conn    *replayConn
+	updates chan description.Topology
+}
+
+func (d *replayDeployment) SelectServer(context.Context, description.ServerSelector) (driver.Server, error) {
+	return d, nil
+}
+
+func (d *replayDeployment) Kind() description.TopologyKind {
+	return description.Single
+}
+
+func (d *replayDeployment) Connection(context.Context) (driver.Connection, error) {
+	return d.conn, nil
+}
+
+func (d *replayDeployment) RTTMonitor() driver.RTTMonitor {
+	return zeroRTTMonitor{}
+}
+
+func (d *replayDeployment) Connect() error {
+	return nil
+}
+
+func (d *replayDeployment) Disconnect(context.Context) error {
+	if d.updates != nil {
+		close(d.updates)
+	}
+	return nil
+}
+
+// Subscribe tells the session timeout, which the client needs to use sessions.
+func (d *replayDeployment) Subscribe() (*driver.Subscription, error) {
+	if d.updates == nil {
+		d.updates = make(chan description.Topology, 1)
+		d.updates <- description.Topology{SessionTimeoutMinutes: replayDescription.SessionTimeoutMinutes}
+	}
+	return &driver.Subscription{Updates: d.updates}, nil
+}
+
+func (d *replayDeployment) Unsubscribe(*driver.Subscription) error {
+	return nil
+}
+
+type zeroRTTMonitor struct{}
+
+func (zeroRTTMonitor) EWMA() time.Duration { return 0 }
+func (zeroRTTMonitor) Min() time.Duration  { return 0 }
+func (zeroRTTMonitor) P90() time.Duration  { return 0 }
+func (zeroRTTMonitor) Stats() string       { return "" }
@@ -0,0 +1,228 @@
+package wrappertest
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// applyUpdate returns a copy of doc with the update operators applied. $setOnInsert is applied only on inserting.
+func applyUpdate(doc bson.D, update bson.D, inserting bool) (bson.D, error) {
+	if len(update) == 0 || !isOperators(update) {
+		return nil, errors.New("update document must contain update operators")
+	}
+	doc = copyDocument(doc)
+	for _, operator := range update {
+		fields, ok := operator.Value.(bson.D)
+		if !ok {
+			return nil, errors.Errorf("%s needs a document", operator.Key)
+		}
+		for _, field := range fields {
+			if field.Key == "_id" && !inserting && operator.Key != "$setOnInsert" {
+				return nil, errors.New("the field _id is immutable")
+			}
+			var err error
+			path := strings.Split(field.Key, ".")
+			switch operator.Key {
+			case "$set":
+				doc, err = setPath(doc, path, field.Value)
+			case "$setOnInsert":
+				if inserting {
+					doc, err = setPath(doc, path, field.Value)
+				}
+			case "$unset":
+				doc = unsetPath(doc, path)
+			case "$inc":
+				doc, err = inc(doc, path, field.Value)
+			case "$push":
+				doc, err = push(doc, path, field.Value)
+			default:
+				return nil, errors.Wrap(UnsupportedErr, operator.Key)
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, field.Key)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// replaceDocument returns the replacement with the _id of doc.
+func replaceDocument(doc bson.D, replacement bson.D) (bson.D, error) {
+	if isOperators(replacement) {
+		return nil, errors.New("replacement document cannot contain update operators")
+	}
+	id, _ := get(doc, "_id")
+	if replacedId, ok := get(replacement, "_id"); ok && !equal(id, replacedId) {
+		return nil, errors.New("the field _id is immutable")
+	}
+	return withId(copyDocument(replacement), id), nil
+}
+
+// withId returns doc with the _id first.
+func withId(doc bson.D, id interface{}) bson.D {
+	result := bson.D{{Key: "_id", Value: id}}
+	for _, element := range doc {
+		if element.Key != "_id" {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+func setPath(v interface{}, path []string, value interface{}) (bson.D, error) {
+	doc, ok := v.(bson.D)
+	if !ok {
+		return nil, errors.New("cannot create a field in a non-document value")
+	}
+	for i, element := range doc {
+		if element.Key != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			doc[i].Value = value
+			return doc, nil
+		}
+		child, err := setChild(element.Value, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		doc[i].Value = child
+		return doc, nil
+	}
+	if len(path) == 1 {
+		return append(doc, bson.E{Key: path[0], Value: value}), nil
+	}
+	child, err := setPath(bson.D{}, path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	return append(doc, bson.E{Key: path[0], Value: child}), nil
+}
+
+func setChild(v interface{}, path []string, value interface{}) (interface{}, error) {
+	array, ok := v.(bson.A)
+	if !ok {
+		return setPath(v, path, value)
+	}
+	index, err := strconv.Atoi(path[0])
+	if err != nil || index < 0 {
+		return nil, errors.Errorf("cannot create the field %s in an array", path[0])
+	}
+	for len(array) <= index {
+		array = append(array, nil)
+	}
+	if len(path) == 1 {
+		array[index] = value
+		return array, nil
+	}
+	child, err := setChild(array[index], path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	array[index] = child
+	return array, nil
+}
+
+func unsetPath(doc bson.D, path []string) bson.D {
+	for i, element := range doc {
+		if element.Key != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return append(doc[:i], doc[i+1:]...)
+		}
+		if child, ok := element.Value.(bson.D); ok {
+			doc[i].Value = unsetPath(child, path[1:])
+		}
+		return doc
+	}
+	return doc
+}
+
+func inc(doc bson.D, path []string, delta interface{}) (bson.D, error) {
+	if typeOrder(delta) != typeOrder(int32(0)) {
+		return nil, errors.New("$inc needs a number")
+	}
+	values := lookup(doc, path)
+	if len(values) == 0 {
+		return setPath(doc, path, delta)
+	}
+	if typeOrder(values[0]) != typeOrder(int32(0)) {
+		return nil, errors.New("cannot apply $inc to a non-numeric value")
+	}
+	return setPath(doc, path, add(values[0], delta))
+}
+
+// add returns the sum of two numbers in the wider type of them, like the server does.
+func add(a, b interface{}) interface{} {
+	switch x := a.(type) {
+	case int32:
+		switch y := b.(type) {
+		case int32:
+			sum := int64(x) + int64(y)
+			if sum >= math.MinInt32 && sum <= math.MaxInt32 {
+				return int32(sum)
+			}
+			return sum
+		case int64:
+			return int64(x) + y
+		}
+	case int64:
+		switch y := b.(type) {
+		case int32:
+			return x + int64(y)
+		case int64:
+			return x + y
+		}
+	}
+	return toFloat(a) + toFloat(b)
+}
+
+func push(doc bson.D, path []string, value interface{}) (bson.D, error) {
+	items := bson.A{value}
+	if modifiers, ok := value.(bson.D); ok && isOperators(modifiers) {
+		each, ok := get(modifiers, "$each")
+		if !ok || len(modifiers) > 1 {
+			return nil, errors.Wrap(UnsupportedErr, "$push modifiers other than $each")
+		}
+		if items, ok = each.(bson.A); !ok {
+			return nil, errors.New("$each needs an array")
+		}
+	}
+	values := lookup(doc, path)
+	if len(values) == 0 {
+		return setPath(doc, path, append(bson.A{}, items...))
+	}
+	array, ok := values[0].(bson.A)
+	if !ok {
+		return nil, errors.New("cannot apply $push to a non-array value")
+	}
+	return setPath(doc, path, append(append(bson.A{}, array...), items...))
+}
+
+// copyDocument returns a deep copy of doc, so that updates do not change the stored documents.
+func copyDocument(doc bson.D) bson.D {
+	return copyValue(doc).(bson.D)
+}
+
+func copyValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case bson.D:
+		result := make(bson.D, len(x))
+		for i, element := range x {
+			result[i] = bson.E{Key: element.Key, Value: copyValue(element.Value)}
+		}
+		return result
+	case bson.A:
+		result := make(bson.A, len(x))
+		for i, item := range x {
+			result[i] = copyValue(item)
+		}
+		return result
+	}
+	return v
+}